@@ -4,53 +4,329 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// CiphertextEncoding is the text encoding of the ciphertexts returned by Encrypt.
+type CiphertextEncoding int32
+
+const (
+	// CiphertextBase64 encodes the ciphertexts in standard base64, without encoding prefix.
+	CiphertextBase64 CiphertextEncoding = iota
+	// CiphertextBase64URL encodes the ciphertexts in unpadded base64url prefixed with '~',
+	// safe in URLs and file names.
+	CiphertextBase64URL
+	// CiphertextHex encodes the ciphertexts in hexadecimal prefixed with '_'.
+	CiphertextHex
+)
+
+// The prefixes are outside of the standard base64 alphabet, so Decrypt tells the encodings apart
+// whatever the encoding set when decrypting. prefixMAC follows the marker of the authenticated
+// ciphertexts, starting with their IV and ending with their MAC. prefixIV marks the older unauthenticated
+// ones starting with their IV, the oldest being encrypted with the first block of the key as IV.
+const (
+	prefixBase64URL = '~'
+	prefixHex       = '_'
+	prefixIV        = '*'
+	prefixMAC       = '!'
+)
+
+// macSize is the size of the HMAC-SHA256 tag, truncated, ending the authenticated ciphertexts.
+const macSize = 16
+
+// TagValDeterministic is the tag value marking the fields encrypted deterministically whatever
+// SetDeterministic, e.g. `log:"enc-det"`, revealing which of them hold equal values.
+const TagValDeterministic = "enc-det"
+
+var deterministic atomic.Bool
+
+// SetDeterministic sets whether Encrypt derives the IV from the key and the plaintext, so that
+// a plaintext always encrypts to the same ciphertext, e.g. to search or group the logs by an
+// encrypted value. It weakens the encryption: anyone reading the logs, even without the key,
+// learns which ciphertexts hold equal plaintexts, and can match a ciphertext to a guessed
+// plaintext encrypted through the logger. By default, the IV is random.
+// The fields tagged TagValDeterministic are always deterministic.
+func SetDeterministic(enabled bool) {
+	deterministic.Store(enabled)
+}
+
+// ciphertextMarker prefixes every ciphertext returned by Encrypt, so that a value already encrypted
+// is recognized and never encrypted twice. Decrypt also accepts the ciphertexts without it.
+const ciphertextMarker = "ENC:"
+
+// IsEncrypted reports whether text is a ciphertext returned by Encrypt, carrying its marker.
+// It only checks the marker, which a plaintext may also start with.
+func IsEncrypted(text string) bool {
+	return strings.HasPrefix(text, ciphertextMarker)
+}
+
+// isEncryptedWith reports whether text is an authenticated ciphertext whose MAC checks with the hex
+// encoded key secretKeyHex. A plaintext merely looking like a ciphertext, e.g. user input, can't forge
+// the MAC and still gets encrypted, as do the older unauthenticated ciphertexts.
+func isEncryptedWith(text, secretKeyHex string) bool {
+	if !strings.HasPrefix(text, ciphertextMarker+string(prefixMAC)) {
+		return false
+	}
+
+	keys, err := deriveKeys(secretKeyHex)
+	if err != nil {
+		return false
+	}
+
+	ciphertext, _, err := decodeCiphertext(text)
+	return err == nil && keys.verify(ciphertext) != nil
+}
+
+// cipherKeys are the subkeys derived from an AES key, so that the key itself is never
+// used by two primitives.
+type cipherKeys struct {
+	enc []byte // AES-CBC encryption, of the size of the key
+	mac []byte // HMAC-SHA256 of the IV and ciphertext
+	siv []byte // HMAC-SHA256 of the plaintext giving the deterministic IV
+}
+
+// deriveKeys decodes the hex encoded AES key secretKeyHex and derives its subkeys with HKDF-SHA256.
+func deriveKeys(secretKeyHex string) (cipherKeys, error) {
+	secretKey, err := hex.DecodeString(secretKeyHex)
+	if err != nil {
+		return cipherKeys{}, err
+	}
+	if _, err := aes.NewCipher(secretKey); err != nil {
+		return cipherKeys{}, err
+	}
+
+	derive := func(info string, size int) []byte {
+		subkey := make([]byte, size)
+		// HKDF reads at most 255 hashes, never failing for a few bytes
+		_, _ = io.ReadFull(hkdf.New(sha256.New, secretKey, nil, []byte(info)), subkey)
+		return subkey
+	}
+	return cipherKeys{
+		enc: derive("cbc-enc", len(secretKey)),
+		mac: derive("cbc-mac", sha256.Size),
+		siv: derive("siv-iv", sha256.Size),
+	}, nil
+}
+
+// sum returns the truncated MAC of data, the IV followed by the ciphertext.
+func (k cipherKeys) sum(data []byte) []byte {
+	mac := hmac.New(sha256.New, k.mac)
+	mac.Write(data)
+	return mac.Sum(nil)[:macSize]
+}
+
+// verify checks the MAC ending ciphertext in constant time and returns the IV followed by the
+// ciphertext without it, or nil if the MAC doesn't match.
+func (k cipherKeys) verify(ciphertext []byte) []byte {
+	if len(ciphertext) < 2*aes.BlockSize+macSize {
+		return nil
+	}
+
+	data, tag := ciphertext[:len(ciphertext)-macSize], ciphertext[len(ciphertext)-macSize:]
+	if !hmac.Equal(tag, k.sum(data)) {
+		return nil
+	}
+	return data
+}
+
+var ciphertextEncoding atomic.Int32
+
+// SetCiphertextEncoding sets the encoding of the ciphertexts returned by Encrypt.
+// Decrypt accepts the ciphertexts of every encoding.
+func SetCiphertextEncoding(enc CiphertextEncoding) {
+	ciphertextEncoding.Store(int32(enc))
+}
+
+// encodeCiphertext encodes ciphertext, starting with its IV and ending with its MAC, in the encoding
+// set by SetCiphertextEncoding, after the marker.
+func encodeCiphertext(ciphertext []byte) string {
+	prefix := ciphertextMarker + string(prefixMAC)
+	switch CiphertextEncoding(ciphertextEncoding.Load()) {
+	case CiphertextBase64URL:
+		return prefix + string(prefixBase64URL) + base64.RawURLEncoding.EncodeToString(ciphertext)
+	case CiphertextHex:
+		return prefix + string(prefixHex) + hex.EncodeToString(ciphertext)
+	default:
+		return prefix + base64.StdEncoding.EncodeToString(ciphertext)
+	}
+}
+
+// ciphertextFormat tells apart the ciphertexts Encrypt returned over time.
+type ciphertextFormat int
+
+const (
+	formatKeyIV         ciphertextFormat = iota // the first block of the key as IV, unauthenticated
+	formatIV                                    // starting with a random IV, unauthenticated
+	formatAuthenticated                         // starting with the IV and ending with the MAC
 )
 
+// decodeCiphertext decodes a ciphertext of any encoding, told by its prefix, with or without the marker.
+// It returns the format of the ciphertext, told by the prefix following the marker.
+func decodeCiphertext(text string) ([]byte, ciphertextFormat, error) {
+	text = strings.TrimPrefix(text, ciphertextMarker)
+	format := formatKeyIV
+	if text != "" {
+		switch text[0] {
+		case prefixIV:
+			format, text = formatIV, text[1:]
+		case prefixMAC:
+			format, text = formatAuthenticated, text[1:]
+		}
+	}
+	if text == "" {
+		return nil, format, ErrDecrypt
+	}
+
+	var ciphertext []byte
+	var err error
+	switch text[0] {
+	case prefixBase64URL:
+		ciphertext, err = base64.RawURLEncoding.DecodeString(text[1:])
+	case prefixHex:
+		ciphertext, err = hex.DecodeString(text[1:])
+	default:
+		ciphertext, err = base64.StdEncoding.DecodeString(text)
+	}
+	return ciphertext, format, err
+}
+
+// validateKeyPlaintext is the text ValidateKey encrypts and decrypts back.
+const validateKeyPlaintext = "go-logging key check"
+
+// ValidateKey checks that secretKeyHex is a hex encoded AES key by encrypting a known plaintext
+// and decrypting it back, returning an error if either fails or the text doesn't round-trip.
+func ValidateKey(secretKeyHex string) error {
+	ciphertext, err := Encrypt(validateKeyPlaintext, secretKeyHex)
+	if err != nil {
+		return fmt.Errorf("invalid encryption key: %w", err)
+	}
+
+	plaintext, err := Decrypt(ciphertext, secretKeyHex)
+	if err != nil {
+		return fmt.Errorf("invalid encryption key: %w", err)
+	}
+	if plaintext != validateKeyPlaintext {
+		return errors.New("invalid encryption key: encryption doesn't round-trip")
+	}
+
+	return nil
+}
+
+// Encrypt encrypts plaintext with the hex encoded AES key secretKeyHex in AES-CBC, authenticated
+// with an HMAC-SHA256. The ciphertext starts with a marker reported by IsEncrypted, and a plaintext
+// that is already a ciphertext for the key, its MAC checking, is returned as is, so that encrypting
+// a value twice, e.g. in a middleware and a handler, still decrypts in one Decrypt.
 func Encrypt(plaintext, secretKeyHex string) (string, error) {
 	if plaintext == "" {
 		return "", nil
 	}
 
-	secretKey, err := hex.DecodeString(secretKeyHex)
+	return encryptCBC(plaintext, secretKeyHex)
+}
+
+// EncryptDeterministic is Encrypt as with SetDeterministic enabled, for the fields tagged TagValDeterministic.
+func EncryptDeterministic(plaintext, secretKeyHex string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	return encryptCBCWith(plaintext, secretKeyHex, true)
+}
+
+// encryptCBC encrypts plaintext, an empty plaintext giving a one block ciphertext.
+func encryptCBC(plaintext, secretKeyHex string) (string, error) {
+	return encryptCBCWith(plaintext, secretKeyHex, deterministic.Load())
+}
+
+// encryptCBCWith encrypts plaintext with a random IV, or one derived from the plaintext when
+// deterministic, and returns the IV followed by the ciphertext and the MAC of both.
+func encryptCBCWith(plaintext, secretKeyHex string, deterministic bool) (string, error) {
+	keys, err := deriveKeys(secretKeyHex)
 	if err != nil {
 		return "", err
 	}
 
-	iv := secretKey[:aes.BlockSize]
+	if isEncryptedWith(plaintext, secretKeyHex) {
+		return plaintext, nil
+	}
+
+	block, err := aes.NewCipher(keys.enc)
+	if err != nil {
+		return "", err
+	}
 
 	bPlaintext := PKCS5Padding([]byte(plaintext), aes.BlockSize)
 
-	block, err := aes.NewCipher(secretKey)
-	if err != nil {
+	ciphertext := make([]byte, aes.BlockSize+len(bPlaintext), aes.BlockSize+len(bPlaintext)+macSize)
+	iv := ciphertext[:aes.BlockSize]
+	if deterministic {
+		// a synthetic IV, equal for equal plaintexts only
+		mac := hmac.New(sha256.New, keys.siv)
+		mac.Write([]byte(plaintext))
+		copy(iv, mac.Sum(nil))
+	} else if _, err := rand.Read(iv); err != nil {
 		return "", err
 	}
 
-	ciphertext := make([]byte, len(bPlaintext))
 	mode := cipher.NewCBCEncrypter(block, iv)
-	mode.CryptBlocks(ciphertext, bPlaintext)
+	mode.CryptBlocks(ciphertext[aes.BlockSize:], bPlaintext)
 
-	// encode base64 and return
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	return encodeCiphertext(append(ciphertext, keys.sum(ciphertext)...)), nil
 }
 
+// Decrypt decrypts a ciphertext returned by Encrypt, checking its MAC before anything else.
+// Any invalid ciphertext returns ErrDecrypt, while an invalid key returns its own error.
+// The unauthenticated ciphertexts of the older versions still decrypt, without such a check.
 func Decrypt(ciphertextBase64, secretKeyHex string) (string, error) {
 	if ciphertextBase64 == "" {
 		return "", nil
 	}
 
-	secretKey, err := hex.DecodeString(secretKeyHex)
+	// the key is checked first, its errors not depending on the ciphertext
+	keys, err := deriveKeys(secretKeyHex)
 	if err != nil {
 		return "", err
 	}
 
-	ciphertextByte, err := base64.StdEncoding.DecodeString(ciphertextBase64)
+	ciphertextByte, format, err := decodeCiphertext(ciphertextBase64)
 	if err != nil {
-		return "", err
+		return "", ErrDecrypt
 	}
 
-	iv := secretKey[:aes.BlockSize]
+	var secretKey, iv []byte
+	switch format {
+	case formatAuthenticated:
+		if ciphertextByte = keys.verify(ciphertextByte); ciphertextByte == nil {
+			return "", ErrDecrypt
+		}
+		secretKey = keys.enc
+		iv, ciphertextByte = ciphertextByte[:aes.BlockSize], ciphertextByte[aes.BlockSize:]
+	case formatIV:
+		if len(ciphertextByte) < aes.BlockSize {
+			return "", ErrDecrypt
+		}
+		secretKey, _ = hex.DecodeString(secretKeyHex)
+		iv, ciphertextByte = ciphertextByte[:aes.BlockSize], ciphertextByte[aes.BlockSize:]
+	default:
+		secretKey, _ = hex.DecodeString(secretKeyHex)
+		iv = secretKey[:aes.BlockSize]
+	}
+
+	if len(ciphertextByte) == 0 || len(ciphertextByte)%aes.BlockSize != 0 {
+		return "", ErrDecrypt
+	}
 
 	block, err := aes.NewCipher(secretKey)
 	if err != nil {
@@ -60,6 +336,10 @@ func Decrypt(ciphertextBase64, secretKeyHex string) (string, error) {
 	mode := cipher.NewCBCDecrypter(block, iv)
 	mode.CryptBlocks(ciphertextByte, ciphertextByte)
 
+	if !validPKCS5Padding(ciphertextByte, aes.BlockSize) {
+		return "", ErrDecrypt
+	}
+
 	return string(PKCS5UnPadding(ciphertextByte)), nil
 }
 
@@ -74,3 +354,23 @@ func PKCS5UnPadding(src []byte) []byte {
 	unpadding := int(src[length-1])
 	return src[:(length - unpadding)]
 }
+
+// validPKCS5Padding reports whether src ends with a well-formed PKCS5 padding.
+// It runs in constant time for a given length of src, so its timing doesn't tell
+// a padding oracle which byte of the padding is wrong.
+func validPKCS5Padding(src []byte, blockSize int) bool {
+	length := len(src)
+	if length < blockSize {
+		return false
+	}
+
+	unpadding := src[length-1]
+	good := subtle.ConstantTimeLessOrEq(1, int(unpadding)) & subtle.ConstantTimeLessOrEq(int(unpadding), blockSize)
+
+	// check the whole last block, only the bytes within the padding counting
+	for i := 1; i <= blockSize; i++ {
+		inPadding := subtle.ConstantTimeLessOrEq(i, int(unpadding))
+		good &= subtle.ConstantTimeSelect(inPadding, subtle.ConstantTimeByteEq(src[length-i], unpadding), 1)
+	}
+	return good == 1
+}
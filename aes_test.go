@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{testKey, testKey + "1011121314151617", testKey + testKey} {
+		if err := ValidateKey(key); err != nil {
+			t.Errorf("ValidateKey of a %d bytes key = %v, want nil", len(key)/2, err)
+		}
+	}
+	for _, key := range []string{"", "not hex", "0001020304", testKey + "10"} {
+		if err := ValidateKey(key); err == nil || !strings.HasPrefix(err.Error(), "invalid encryption key") {
+			t.Errorf("ValidateKey(%q) = %v, want an invalid key error", key, err)
+		}
+	}
+}
+
+func TestSetKeyEncryptValidated(t *testing.T) {
+	prev := keyEncrypt
+	keyEncrypt = nil
+	t.Cleanup(func() { keyEncrypt = prev })
+
+	if err := SetKeyEncryptValidated("0001020304"); err == nil {
+		t.Fatal("SetKeyEncryptValidated of a short key succeeded, want an error")
+	}
+	if HasKeyEncrypt() {
+		t.Fatal("an invalid key was set")
+	}
+
+	if err := SetKeyEncryptValidated(testKey); err != nil {
+		t.Fatal(err)
+	}
+	if !HasKeyEncrypt() || *keyEncrypt != testKey {
+		t.Error("the valid key wasn't set")
+	}
+}
+
+func TestSetCiphertextEncoding(t *testing.T) {
+	t.Cleanup(func() { SetCiphertextEncoding(CiphertextBase64) })
+
+	encodings := []struct {
+		enc    CiphertextEncoding
+		prefix string
+	}{
+		{CiphertextBase64, "ENC:!"},
+		{CiphertextBase64URL, "ENC:!~"},
+		{CiphertextHex, "ENC:!_"},
+	}
+	var ciphertexts []string
+	for _, e := range encodings {
+		SetCiphertextEncoding(e.enc)
+		for i := 0; i < 50; i++ {
+			plaintext := strings.Repeat("x", i+1)
+			ciphertext, err := Encrypt(plaintext, testKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(ciphertext, e.prefix) {
+				t.Fatalf("encoding %d: ciphertext %q, want the prefix %q", e.enc, ciphertext, e.prefix)
+			}
+			if e.enc == CiphertextBase64URL && strings.ContainsAny(ciphertext, "+/=") {
+				t.Fatalf("base64url ciphertext %q holds +, / or =", ciphertext)
+			}
+			if mustDecrypt(t, ciphertext) != plaintext {
+				t.Fatalf("encoding %d: %q doesn't round-trip", e.enc, plaintext)
+			}
+			ciphertexts = append(ciphertexts, ciphertext)
+		}
+	}
+
+	// the prefix tells Decrypt the encoding, whatever the one set now
+	SetCiphertextEncoding(CiphertextBase64)
+	for _, ciphertext := range ciphertexts {
+		mustDecrypt(t, ciphertext)
+	}
+}
+
+func TestDecryptUniformError(t *testing.T) {
+	ciphertext, err := Encrypt("4111111111111111", testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, "ENC:!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamper := func(i int) string {
+		b := bytes.Clone(raw)
+		b[i] ^= 1
+		return "ENC:!" + base64.StdEncoding.EncodeToString(b)
+	}
+
+	tests := map[string]string{
+		"truncated":           ciphertext[:len(ciphertext)-8],
+		"not base64":          "ENC:!not base64",
+		"tampered iv":         tamper(0),
+		"tampered last block": tamper(len(raw) - 17),
+		"tampered mac":        tamper(len(raw) - 1),
+		"legacy garbage":      "ENC:" + base64.StdEncoding.EncodeToString([]byte("garbage")),
+	}
+	for name, input := range tests {
+		if _, err := Decrypt(input, testKey); err != ErrDecrypt {
+			t.Errorf("%s: err = %v, want ErrDecrypt", name, err)
+		}
+	}
+
+	if _, err := Decrypt(ciphertext, "101112131415161718191a1b1c1d1e1f"); err != ErrDecrypt {
+		t.Errorf("wrong key: err = %v, want ErrDecrypt", err)
+	}
+	if mustDecrypt(t, ciphertext) != "4111111111111111" {
+		t.Error("the valid ciphertext doesn't decrypt")
+	}
+}
+
+func TestEncryptIdempotent(t *testing.T) {
+	type payment struct {
+		Card   string   `encrypt:"true"`
+		Tokens []string `encrypt:"true"`
+		Note   *string  `encrypt:"true"`
+	}
+	note := "note"
+	input := payment{Card: "4111", Tokens: []string{"tok"}, Note: &note}
+
+	once, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twice, err := StructEncryptTag(once, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twice.Card != once.Card || twice.Tokens[0] != once.Tokens[0] || *twice.Note != *once.Note {
+		t.Errorf("encrypted twice %+v, want the ciphertexts of the first pass %+v", twice, once)
+	}
+	decrypted, err := StructDecryptTag(twice, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Card != "4111" || decrypted.Tokens[0] != "tok" || *decrypted.Note != "note" {
+		t.Errorf("decrypted %+v, want the input after a single decrypt", decrypted)
+	}
+	if !IsEncrypted(once.Card) || IsEncrypted("4111") {
+		t.Error("IsEncrypted doesn't tell the ciphertext from the plaintext")
+	}
+
+	// a ciphertext of another key is encrypted again, as is a value merely carrying the marker
+	other, err := Encrypt("4111", "101112131415161718191a1b1c1d1e1f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := strings.Replace(once.Card, "ENC:!", "ENC:", 1)
+	for _, input := range []string{other, legacy, "ENC:!4111", "ENC:4111"} {
+		encrypted, err := Encrypt(input, testKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encrypted == input || mustDecrypt(t, encrypted) != input {
+			t.Errorf("Encrypt(%q) = %q, want it encrypted", input, encrypted)
+		}
+	}
+}
+
+func TestIsEncryptedWithForgedInput(t *testing.T) {
+	b := make([]byte, 64)
+	for i := 0; i < 1000; i++ {
+		payload := b[:1+i%len(b)]
+		rand.Read(payload)
+		for _, input := range []string{
+			"ENC:!" + base64.StdEncoding.EncodeToString(payload),
+			"ENC:!" + base64.RawURLEncoding.EncodeToString(payload),
+			"ENC:!_" + hex.EncodeToString(payload),
+			"ENC:" + base64.StdEncoding.EncodeToString(payload),
+		} {
+			if isEncryptedWith(input, testKey) {
+				t.Fatalf("isEncryptedWith(%q) = true, want a random input encrypted", input)
+			}
+		}
+	}
+}
+
+func TestSetDeterministic(t *testing.T) {
+	t.Cleanup(func() { SetDeterministic(false) })
+
+	random1, _ := Encrypt("hello", testKey)
+	random2, _ := Encrypt("hello", testKey)
+	if random1 == random2 {
+		t.Error("equal ciphertexts with random IVs")
+	}
+
+	SetDeterministic(true)
+	det1, _ := Encrypt("hello", testKey)
+	det2, _ := Encrypt("hello", testKey)
+	other, _ := Encrypt("world", testKey)
+	otherKey, _ := Encrypt("hello", "101112131415161718191a1b1c1d1e1f")
+	if det1 != det2 {
+		t.Errorf("deterministic ciphertexts %s and %s, want them equal", det1, det2)
+	}
+	if det1 == other || det1 == otherKey {
+		t.Error("equal deterministic ciphertexts for another plaintext or key")
+	}
+	for _, ciphertext := range []string{random1, random2, det1} {
+		if mustDecrypt(t, ciphertext) != "hello" {
+			t.Errorf("%s doesn't decrypt to hello", ciphertext)
+		}
+	}
+
+	// the IV is the HMAC of the plaintext with the SIV subkey, never with the key itself
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(det1, "ENC:!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := deriveKeys(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := hex.DecodeString(testKey)
+	siv := hmac.New(sha256.New, keys.siv)
+	siv.Write([]byte("hello"))
+	direct := hmac.New(sha256.New, key)
+	direct.Write([]byte("hello"))
+	if iv := raw[:aes.BlockSize]; !bytes.Equal(iv, siv.Sum(nil)[:aes.BlockSize]) || bytes.Equal(iv, direct.Sum(nil)[:aes.BlockSize]) {
+		t.Errorf("IV %x, want it derived with the SIV subkey", iv)
+	}
+}
+
+func TestDeterministicTag(t *testing.T) {
+	type dto struct {
+		Email string `log:"enc-det"`
+		Card  string `log:"true"`
+	}
+
+	first, err := StructEncryptTag(dto{Email: "bob@example.com", Card: "4111"}, testKey, "log", "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := StructEncryptTag(dto{Email: "bob@example.com", Card: "4111"}, testKey, "log", "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Email != second.Email || !IsEncrypted(first.Email) {
+		t.Errorf("emails %s and %s, want equal ciphertexts", first.Email, second.Email)
+	}
+	if first.Card == second.Card {
+		t.Error("equal card ciphertexts without the deterministic tag")
+	}
+
+	decrypted, err := StructDecryptTag(first, testKey, "log", "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Email != "bob@example.com" || decrypted.Card != "4111" {
+		t.Errorf("decrypted %+v, want the plaintexts", decrypted)
+	}
+}
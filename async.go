@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrWriterClosed is returned when writing to a closed AsyncWriter.
+var ErrWriterClosed = errors.New("async writer is closed")
+
+// OverflowPolicy decides what an AsyncWriter does when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the log call until the buffer has room.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop drops the entry and counts it in Dropped.
+	OverflowDrop
+)
+
+// AsyncWriter is an io.Writer that enqueues entries in a buffered channel
+// drained to the underlying writer by a background goroutine.
+type AsyncWriter struct {
+	w       io.Writer
+	policy  OverflowPolicy
+	entries chan []byte
+	done    chan struct{}
+	exited  chan struct{}
+	dropped atomic.Uint64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending int
+	closed  bool
+	sending sync.WaitGroup // Writes between their closed check and their send, which may block
+}
+
+// NewAsyncWriter creates an AsyncWriter buffering up to buffer entries in front of w.
+func NewAsyncWriter(w io.Writer, buffer int, policy OverflowPolicy) *AsyncWriter {
+	a := &AsyncWriter{
+		w:       w,
+		policy:  policy,
+		entries: make(chan []byte, buffer),
+		done:    make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	a.cond = sync.NewCond(&a.mu)
+
+	go a.drain()
+	return a
+}
+
+// Write enqueues a copy of p to be written by the background goroutine.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return 0, ErrWriterClosed
+	}
+	a.pending++
+	a.sending.Add(1)
+	a.mu.Unlock()
+	defer a.sending.Done()
+
+	// zerolog reuses p once Write returns
+	entry := append([]byte(nil), p...)
+
+	if a.policy == OverflowDrop {
+		select {
+		case a.entries <- entry:
+		default:
+			a.dropped.Add(1)
+			a.entryDone()
+		}
+		return len(p), nil
+	}
+
+	a.entries <- entry
+	return len(p), nil
+}
+
+// Flush waits until all entries enqueued so far are written.
+func (a *AsyncWriter) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.pending > 0 {
+		a.cond.Wait()
+	}
+	return nil
+}
+
+// Sync flushes the pending entries then syncs the underlying writer if it has a Sync method.
+func (a *AsyncWriter) Sync() error {
+	if err := a.Flush(); err != nil {
+		return err
+	}
+	if s, ok := a.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close rejects new entries, drains the remaining ones and stops the background goroutine.
+// It doesn't close the underlying writer.
+func (a *AsyncWriter) Close() error {
+	_, err := a.CloseContext(context.Background())
+	return err
+}
+
+// CloseContext is Close giving up draining once ctx is done, to bound the shutdown time.
+// The entries still buffered, along with those of the Writes blocked on the full buffer, are then
+// dropped, counted in Dropped and their number returned along with ctx.Err().
+// An entry being written to the underlying writer completes in the background.
+func (a *AsyncWriter) CloseContext(ctx context.Context) (int, error) {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return 0, nil
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	flushed := make(chan struct{})
+	go func() {
+		_ = a.Flush()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		close(a.done)
+		<-a.exited
+		return 0, nil
+	case <-ctx.Done():
+	}
+
+	close(a.done)
+	// a Write blocked on the full buffer sends once there is room, so entries is closed
+	// only after every Write started before Close has sent its entry
+	go func() {
+		a.sending.Wait()
+		close(a.entries)
+	}()
+	dropped := 0
+	for range a.entries {
+		dropped++
+		a.entryDone()
+	}
+	a.dropped.Add(uint64(dropped))
+	return dropped, ctx.Err()
+}
+
+// Dropped returns the number of entries dropped because the buffer was full or CloseContext timed out.
+func (a *AsyncWriter) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// drain writes the enqueued entries to the underlying writer until Close.
+func (a *AsyncWriter) drain() {
+	defer close(a.exited)
+	for {
+		select {
+		case entry, ok := <-a.entries:
+			if !ok {
+				return
+			}
+			_, _ = a.w.Write(entry)
+			a.entryDone()
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// entryDone marks one pending entry as handled.
+func (a *AsyncWriter) entryDone() {
+	a.mu.Lock()
+	a.pending--
+	if a.pending == 0 {
+		a.cond.Broadcast()
+	}
+	a.mu.Unlock()
+}
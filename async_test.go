@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks every Write until release is closed.
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	n       int
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	w.n++
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+func TestAsyncWriterCloseFlushesAll(t *testing.T) {
+	const n = 1000
+	l, buf := newTestLogger(WithAsyncWriter(16))
+
+	for i := 0; i < n; i++ {
+		l.Info().Int("i", i).Msg("entry")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := decodeLines(t, buf)
+	if len(entries) != n {
+		t.Fatalf("got %d entries, want %d", len(entries), n)
+	}
+	for i, entry := range entries {
+		if entry["i"] != float64(i) {
+			t.Fatalf("entry %d = %v, want the entries in order", i, entry)
+		}
+	}
+}
+
+func TestAsyncWriterFlush(t *testing.T) {
+	buf := &bytes.Buffer{}
+	a := NewAsyncWriter(buf, 4, OverflowBlock)
+	defer a.Close()
+
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(a, "line %d\n", i)
+	}
+	if err := a.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := bytes.Count(buf.Bytes(), []byte("\n")); got != 10 {
+		t.Errorf("got %d lines after Flush, want 10", got)
+	}
+}
+
+func TestAsyncWriterOverflowDrop(t *testing.T) {
+	w := &blockingWriter{release: make(chan struct{})}
+	a := NewAsyncWriter(w, 2, OverflowDrop)
+
+	// one entry held by the blocked drain goroutine at most, two buffered, the others dropped
+	for i := 0; i < 10; i++ {
+		if _, err := a.Write([]byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(w.release)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if dropped := a.Dropped(); dropped < 7 || int(dropped)+w.n != 10 {
+		t.Errorf("dropped %d and wrote %d, want 10 entries accounted for with at least 7 dropped", dropped, w.n)
+	}
+}
+
+func TestAsyncWriterWriteAfterClose(t *testing.T) {
+	a := NewAsyncWriter(&bytes.Buffer{}, 1, OverflowBlock)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Write([]byte("late")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("err = %v, want ErrWriterClosed", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Errorf("second Close = %v, want nil", err)
+	}
+}
+
+func TestAsyncWriterCloseContextTimeout(t *testing.T) {
+	w := &blockingWriter{release: make(chan struct{})}
+	a := NewAsyncWriter(w, 2, OverflowBlock)
+
+	// the third Write returns once the drain goroutine holds the first entry, two left buffered
+	for i := 0; i < 3; i++ {
+		if _, err := a.Write([]byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	dropped, err := a.CloseContext(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CloseContext took %v, want it bounded by the deadline", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if dropped != 2 || a.Dropped() != 2 {
+		t.Errorf("dropped %d, Dropped = %d, want the 2 buffered entries", dropped, a.Dropped())
+	}
+
+	close(w.release)
+	if dropped, err := a.CloseContext(context.Background()); dropped != 0 || err != nil {
+		t.Errorf("second CloseContext = %d, %v, want 0, nil", dropped, err)
+	}
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// LogCapture records the entries written by a logger, to assert on them in tests.
+type LogCapture struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// CaptureLogs returns a logger writing its entries to the returned LogCapture.
+func CaptureLogs() (*Logger, *LogCapture) {
+	c := &LogCapture{}
+	return NewLogger("capture", WithWriter(c)), c
+}
+
+// Write records the entry p.
+func (c *LogCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lines = append(c.lines, strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// Entries returns the entries recorded so far, decoded from JSON, in write order.
+// An entry that isn't a JSON object is returned as an empty map.
+func (c *LogCapture) Entries() []map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]map[string]interface{}, len(c.lines))
+	for i, line := range c.lines {
+		entries[i] = map[string]interface{}{}
+		_ = json.Unmarshal([]byte(line), &entries[i])
+	}
+	return entries
+}
+
+// Contains reports whether an entry at level contains substring, in its message or any other field.
+func (c *LogCapture) Contains(level zerolog.Level, substring string) bool {
+	for i, entry := range c.Entries() {
+		if entry[zerolog.LevelFieldName] == level.String() && strings.Contains(c.line(i), substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// Field returns the value of the field key of the entry idx, or nil when either is missing.
+func (c *LogCapture) Field(idx int, key string) interface{} {
+	entries := c.Entries()
+	if idx < 0 || idx >= len(entries) {
+		return nil
+	}
+	return entries[idx][key]
+}
+
+// line returns the entry idx as written.
+func (c *LogCapture) line(idx int) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lines[idx]
+}
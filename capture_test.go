@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestCaptureLogs(t *testing.T) {
+	l, capture := CaptureLogs()
+
+	l.Info().Str("user", "bob").Msg("logged in")
+	l.Warn().Int("attempts", 3).Msg("slow login")
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0]["message"] != "logged in" || entries[1]["level"] != "warn" {
+		t.Errorf("entries = %v, want them in write order", entries)
+	}
+
+	tests := []struct {
+		level     zerolog.Level
+		substring string
+		want      bool
+	}{
+		{zerolog.InfoLevel, "logged in", true},
+		{zerolog.InfoLevel, "bob", true},
+		{zerolog.WarnLevel, "bob", false},
+		{zerolog.WarnLevel, "slow", true},
+		{zerolog.ErrorLevel, "slow", false},
+	}
+	for _, tt := range tests {
+		if got := capture.Contains(tt.level, tt.substring); got != tt.want {
+			t.Errorf("Contains(%s, %q) = %v, want %v", tt.level, tt.substring, got, tt.want)
+		}
+	}
+
+	if got := capture.Field(0, "user"); got != "bob" {
+		t.Errorf("Field(0, user) = %v, want bob", got)
+	}
+	if got := capture.Field(1, "attempts"); got != float64(3) {
+		t.Errorf("Field(1, attempts) = %v, want 3", got)
+	}
+	for _, idx := range []int{-1, 2} {
+		if got := capture.Field(idx, "user"); got != nil {
+			t.Errorf("Field(%d, user) = %v, want nil", idx, got)
+		}
+	}
+	if got := capture.Field(0, "missing"); got != nil {
+		t.Errorf("Field(0, missing) = %v, want nil", got)
+	}
+}
+
+func TestCaptureLogsConcurrent(t *testing.T) {
+	l, capture := CaptureLogs()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				l.Info().Msg("entry")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(capture.Entries()); got != 100 {
+		t.Errorf("got %d entries, want 100", got)
+	}
+}
@@ -1,9 +1,23 @@
 package logger
 
 const (
-	TagNameEncrypt  = "encrypt"
-	TagValEncrypt   = "true"
-	KeyRequestBody  = "request_body"
-	KeyResponseBody = "response_body"
-	KeyTraceInfo    = "trace_info"
+	TagNameEncrypt      = "encrypt"
+	TagValEncrypt       = "true"
+	KeyRequestBody      = "request_body"
+	KeyResponseBody     = "response_body"
+	KeyTraceInfo        = "trace_info"
+	KeyRequestID        = "request_id"
+	KeyTraceID          = "trace_id"
+	KeyUserID           = "user_id"
+	KeyMethod           = "method"
+	KeyLatency          = "latency_ms"
+	KeyPath             = "path"
+	KeyURL              = "url"
+	KeyStatus           = "status"
+	KeyGoroutineID      = "gid"
+	KeyRequestBytes     = "req_bytes"
+	KeyResponseBytes    = "resp_bytes"
+	KeyValidationErrors = "validation_errors"
+	KeyPayload          = "payload"
+	KeyErrType          = "err_type"
 )
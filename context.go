@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"time"
@@ -14,221 +15,261 @@ type Context struct {
 }
 
 func (c Context) Logger() Logger {
-	return Logger{logger: c.l.logger}
+	return c.l
 }
 
 func (c Context) Fields(fields interface{}) Context {
-	return Context{Logger{c.l.logger.With().Fields(fields).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Fields(fields).Logger())}
 }
 
 func (c Context) Array(key string, arr zerolog.LogArrayMarshaler) Context {
-	return Context{Logger{c.l.logger.With().Array(key, arr).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Array(key, arr).Logger())}
 }
 
 func (c Context) Object(key string, obj zerolog.LogObjectMarshaler) Context {
-	return Context{Logger{c.l.logger.With().Object(key, obj).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Object(key, obj).Logger())}
 }
 
 func (c Context) EmbedObject(obj zerolog.LogObjectMarshaler) Context {
-	return Context{Logger{c.l.logger.With().EmbedObject(obj).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().EmbedObject(obj).Logger())}
 }
 
 func (c Context) Str(key, val string) Context {
-	return Context{Logger{c.l.logger.With().Str(key, val).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Str(key, val).Logger())}
 }
 
 func (c Context) Strs(key string, vals []string) Context {
-	return Context{Logger{c.l.logger.With().Strs(key, vals).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Strs(key, vals).Logger())}
 }
 
 func (c Context) Stringer(key string, val fmt.Stringer) Context {
-	return Context{Logger{c.l.logger.With().Stringer(key, val).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Stringer(key, val).Logger())}
 }
 
+// Bytes adds val in the encoding set by SetBinaryEncoding, as a string by default.
 func (c Context) Bytes(key string, val []byte) Context {
-	return Context{Logger{c.l.logger.With().Bytes(key, val).Logger()}}
+	switch currentBinaryEncoding() {
+	case BinaryBase64:
+		return c.Str(key, base64.StdEncoding.EncodeToString(val))
+	case BinaryHex:
+		return c.Hex(key, val)
+	}
+	return Context{c.l.derive(c.l.logger.With().Bytes(key, val).Logger())}
 }
 
 func (c Context) Hex(key string, val []byte) Context {
-	return Context{Logger{c.l.logger.With().Hex(key, val).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Hex(key, val).Logger())}
 }
 
 func (c Context) RawJSON(key string, b []byte) Context {
-	return Context{Logger{c.l.logger.With().RawJSON(key, b).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().RawJSON(key, b).Logger())}
 }
 
 func (c Context) AnErr(key string, err error) Context {
-	return Context{Logger{c.l.logger.With().AnErr(key, err).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().AnErr(key, err).Logger())}
 }
 
 func (c Context) Errs(key string, errs []error) Context {
-	return Context{Logger{c.l.logger.With().Errs(key, errs).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Errs(key, errs).Logger())}
 }
 
+// Err adds err under zerolog.ErrorFieldName, with its stack under zerolog.ErrorStackFieldName
+// when it carries one, to every event of the logger, e.g. while handling err over several lines.
+// Unlike with zerolog's Stack, the errors added later to the events get no stack.
 func (c Context) Err(err error) Context {
-	return Context{Logger{c.l.logger.With().Err(err).Logger()}}
+	zc := c.l.logger.With()
+	// marshaled here rather than with zerolog's Stack, which would add a stack to the later Err of the events too
+	if err != nil && zerolog.ErrorStackMarshaler != nil {
+		switch m := zerolog.ErrorStackMarshaler(err).(type) {
+		case nil:
+		case zerolog.LogObjectMarshaler:
+			zc = zc.Object(zerolog.ErrorStackFieldName, m)
+		case error:
+			zc = zc.Str(zerolog.ErrorStackFieldName, m.Error())
+		case string:
+			zc = zc.Str(zerolog.ErrorStackFieldName, m)
+		default:
+			zc = zc.Interface(zerolog.ErrorStackFieldName, m)
+		}
+	}
+	return Context{c.l.derive(zc.Err(err).Logger())}
 }
 
 func (c Context) Ctx(ctx context.Context) Context {
-	return Context{Logger{c.l.logger.With().Ctx(ctx).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Ctx(ctx).Logger())}
 }
 
 func (c Context) Bool(key string, b bool) Context {
-	return Context{Logger{c.l.logger.With().Bool(key, b).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Bool(key, b).Logger())}
 }
 
 func (c Context) Bools(key string, b []bool) Context {
-	return Context{Logger{c.l.logger.With().Bools(key, b).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Bools(key, b).Logger())}
 }
 
 func (c Context) Int(key string, i int) Context {
-	return Context{Logger{c.l.logger.With().Int(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Int(key, i).Logger())}
 }
 
 func (c Context) Ints(key string, i []int) Context {
-	return Context{Logger{c.l.logger.With().Ints(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Ints(key, i).Logger())}
 }
 
 func (c Context) Int8(key string, i int8) Context {
-	return Context{Logger{c.l.logger.With().Int8(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Int8(key, i).Logger())}
 }
 
 func (c Context) Ints8(key string, i []int8) Context {
-	return Context{Logger{c.l.logger.With().Ints8(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Ints8(key, i).Logger())}
 }
 
 func (c Context) Int16(key string, i int16) Context {
-	return Context{Logger{c.l.logger.With().Int16(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Int16(key, i).Logger())}
 }
 
 func (c Context) Ints16(key string, i []int16) Context {
-	return Context{Logger{c.l.logger.With().Ints16(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Ints16(key, i).Logger())}
 }
 
 func (c Context) Int32(key string, i int32) Context {
-	return Context{Logger{c.l.logger.With().Int32(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Int32(key, i).Logger())}
 }
 
 func (c Context) Ints32(key string, i []int32) Context {
-	return Context{Logger{c.l.logger.With().Ints32(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Ints32(key, i).Logger())}
 }
 
 func (c Context) Int64(key string, i int64) Context {
-	return Context{Logger{c.l.logger.With().Int64(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Int64(key, i).Logger())}
 }
 
 func (c Context) Ints64(key string, i []int64) Context {
-	return Context{Logger{c.l.logger.With().Ints64(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Ints64(key, i).Logger())}
 }
 
 func (c Context) Uint(key string, i uint) Context {
-	return Context{Logger{c.l.logger.With().Uint(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uint(key, i).Logger())}
 }
 
 func (c Context) Uints(key string, i []uint) Context {
-	return Context{Logger{c.l.logger.With().Uints(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uints(key, i).Logger())}
 }
 
 func (c Context) Uint8(key string, i uint8) Context {
-	return Context{Logger{c.l.logger.With().Uint8(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uint8(key, i).Logger())}
 }
 
 func (c Context) Uints8(key string, i []uint8) Context {
-	return Context{Logger{c.l.logger.With().Uints8(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uints8(key, i).Logger())}
 }
 
 func (c Context) Uint16(key string, i uint16) Context {
-	return Context{Logger{c.l.logger.With().Uint16(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uint16(key, i).Logger())}
 }
 
 func (c Context) Uints16(key string, i []uint16) Context {
-	return Context{Logger{c.l.logger.With().Uints16(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uints16(key, i).Logger())}
 }
 
 func (c Context) Uint32(key string, i uint32) Context {
-	return Context{Logger{c.l.logger.With().Uint32(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uint32(key, i).Logger())}
 }
 
 func (c Context) Uints32(key string, i []uint32) Context {
-	return Context{Logger{c.l.logger.With().Uints32(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uints32(key, i).Logger())}
 }
 
 func (c Context) Uint64(key string, i uint64) Context {
-	return Context{Logger{c.l.logger.With().Uint64(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uint64(key, i).Logger())}
 }
 
 func (c Context) Uints64(key string, i []uint64) Context {
-	return Context{Logger{c.l.logger.With().Uints64(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Uints64(key, i).Logger())}
 }
 
 func (c Context) Float32(key string, f float32) Context {
-	return Context{Logger{c.l.logger.With().Float32(key, f).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Float32(key, f).Logger())}
 }
 
 func (c Context) Floats32(key string, f []float32) Context {
-	return Context{Logger{c.l.logger.With().Floats32(key, f).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Floats32(key, f).Logger())}
 }
 
 func (c Context) Float64(key string, f float64) Context {
-	return Context{Logger{c.l.logger.With().Float64(key, f).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Float64(key, f).Logger())}
 }
 
 func (c Context) Floats64(key string, f []float64) Context {
-	return Context{Logger{c.l.logger.With().Floats64(key, f).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Floats64(key, f).Logger())}
 }
 
 func (c Context) Timestamp() Context {
-	return Context{Logger{c.l.logger.With().Timestamp().Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Timestamp().Logger())}
 }
 
 func (c Context) Time(key string, t time.Time) Context {
-	return Context{Logger{c.l.logger.With().Time(key, t).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Time(key, t).Logger())}
 }
 
 func (c Context) Times(key string, t []time.Time) Context {
-	return Context{Logger{c.l.logger.With().Times(key, t).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Times(key, t).Logger())}
 }
 
 func (c Context) Dur(key string, d time.Duration) Context {
-	return Context{Logger{c.l.logger.With().Dur(key, d).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Dur(key, d).Logger())}
 }
 
 func (c Context) Durs(key string, d []time.Duration) Context {
-	return Context{Logger{c.l.logger.With().Durs(key, d).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Durs(key, d).Logger())}
 }
 
 func (c Context) Interface(key string, i interface{}) Context {
-	return Context{Logger{c.l.logger.With().Interface(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Interface(key, i).Logger())}
 }
 
 func (c Context) Type(key string, val interface{}) Context {
-	return Context{Logger{c.l.logger.With().Type(key, val).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Type(key, val).Logger())}
 }
 
 func (c Context) Any(key string, i interface{}) Context {
-	return Context{Logger{c.l.logger.With().Any(key, i).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Any(key, i).Logger())}
 }
 
 func (c Context) Caller() Context {
-	return Context{Logger{c.l.logger.With().Caller().Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Caller().Logger())}
 }
 
 func (c Context) CallerWithSkipFrameCount(skipFrameCount int) Context {
-	return Context{Logger{c.l.logger.With().CallerWithSkipFrameCount(skipFrameCount).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().CallerWithSkipFrameCount(skipFrameCount).Logger())}
 }
 
 func (c Context) Stack() Context {
-	return Context{Logger{c.l.logger.With().Stack().Logger()}}
+	return Context{c.l.derive(c.l.logger.With().Stack().Logger())}
 }
 
 func (c Context) IPAddr(key string, ip net.IP) Context {
-	return Context{Logger{c.l.logger.With().IPAddr(key, ip).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().IPAddr(key, ip).Logger())}
 }
 
 func (c Context) IPPrefix(key string, pfx net.IPNet) Context {
-	return Context{Logger{c.l.logger.With().IPPrefix(key, pfx).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().IPPrefix(key, pfx).Logger())}
 }
 
 func (c Context) MACAddr(key string, ha net.HardwareAddr) Context {
-	return Context{Logger{c.l.logger.With().MACAddr(key, ha).Logger()}}
+	return Context{c.l.derive(c.l.logger.With().MACAddr(key, ha).Logger())}
+}
+
+// RequestID adds the request ID under KeyRequestID.
+func (c Context) RequestID(id string) Context {
+	return c.Str(KeyRequestID, id)
+}
+
+// UserID adds the user ID under KeyUserID.
+func (c Context) UserID(id string) Context {
+	return c.Str(KeyUserID, id)
+}
+
+// Method adds the request method under KeyMethod.
+func (c Context) Method(method string) Context {
+	return c.Str(KeyMethod, method)
 }
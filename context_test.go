@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestContextScopedLogger(t *testing.T) {
+	l, buf := newTestLogger()
+	scoped := l.With().RequestID("r1").UserID("u1").Method("GET").Str("route", "/users").Logger()
+
+	scoped.Info().Msg("first")
+	scoped.Warn().Msg("second")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for _, entry := range entries {
+		if entry[KeyRequestID] != "r1" || entry[KeyUserID] != "u1" || entry[KeyMethod] != "GET" || entry["route"] != "/users" {
+			t.Errorf("entry = %v, want the request fields", entry)
+		}
+	}
+}
+
+func TestContextBranchesAreIndependent(t *testing.T) {
+	l, buf := newTestLogger()
+	base := l.With().RequestID("r1")
+	a := base.Str("branch", "a").Logger()
+	b := base.Str("branch", "b").Logger()
+
+	a.Info().Msg("a")
+	b.Info().Msg("b")
+	l.Info().Msg("parent")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0]["branch"] != "a" || entries[1]["branch"] != "b" {
+		t.Errorf("branches = %v, %v, want a and b", entries[0]["branch"], entries[1]["branch"])
+	}
+	if _, ok := entries[2][KeyRequestID]; ok {
+		t.Errorf("parent entry = %v, want it without the scoped fields", entries[2])
+	}
+}
+
+func TestContextErr(t *testing.T) {
+	l, buf := newTestLogger()
+	prev := zerolog.ErrorStackMarshaler
+	zerolog.ErrorStackMarshaler = func(err error) interface{} { return "stack of " + err.Error() }
+	t.Cleanup(func() { zerolog.ErrorStackMarshaler = prev })
+
+	handling := l.With().Err(errors.New("boom")).Logger()
+	handling.Warn().Msg("retrying")
+	handling.Error().Err(errors.New("again")).Msg("giving up")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0][zerolog.ErrorFieldName] != "boom" || entries[0][zerolog.ErrorStackFieldName] != "stack of boom" {
+		t.Errorf("first entry = %v, want the baked-in error and its stack", entries[0])
+	}
+	if entries[1][zerolog.ErrorStackFieldName] != "stack of boom" {
+		t.Errorf("second entry = %v, want the stack of the baked-in error only", entries[1])
+	}
+	if !strings.Contains(buf.String(), `"error":"boom"`) {
+		t.Errorf("output = %s, want the baked-in error on every entry", buf.String())
+	}
+}
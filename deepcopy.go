@@ -2,9 +2,16 @@ package logger
 
 import (
 	"reflect"
-	"time"
+	"sync"
 )
 
+// visitedPool reuses the maps tracking already copied pointers across Copy calls.
+var visitedPool = sync.Pool{
+	New: func() interface{} {
+		return make(map[visitKey]reflect.Value)
+	},
+}
+
 // Interface for delegating copy process to type
 type Interface interface {
 	DeepCopy() interface{}
@@ -17,7 +24,9 @@ func Iface(iface interface{}) interface{} {
 
 // Copy creates a deep copy of whatever is passed to it and returns the copy
 // in an interface{}.  The returned value will need to be asserted to the
-// correct type.
+// correct type. Unexported struct fields are left zero, but for the exported
+// fields of embedded structs and the types registered with RegisterOpaqueType,
+// like time.Time, which are copied whole.
 func Copy(src interface{}) interface{} {
 	if src == nil {
 		return nil
@@ -30,20 +39,43 @@ func Copy(src interface{}) interface{} {
 	cpy := reflect.New(original.Type()).Elem()
 
 	// Recursively copy the original.
-	copyRecursive(original, cpy)
+	copyInto(original, cpy)
 
 	// Return the copy as an interface.
 	return cpy.Interface()
 }
 
+// DeepCopy is Copy returning the copy as a T. A nil input returns the zero T.
+func DeepCopy[T any](input T) T {
+	cpy, _ := Copy(input).(T)
+	return cpy
+}
+
+// copyInto deep copies original into the settable cpy, reusing copies of already seen
+// pointers, and maps and slices of interfaces, so that cyclic graphs terminate.
+// The other maps and slices are copied each time, so that the tag walkers never
+// encrypt the same string twice.
+func copyInto(original, cpy reflect.Value) {
+	visited := visitedPool.Get().(map[visitKey]reflect.Value)
+	defer func() {
+		clear(visited)
+		visitedPool.Put(visited)
+	}()
+
+	copyRecursive(original, cpy, visited)
+}
+
 // copyRecursive does the actual copying of the interface. It currently has
 // limited support for what it can handle. Add as needed.
-func copyRecursive(original, cpy reflect.Value) {
+func copyRecursive(original, cpy reflect.Value, visited map[visitKey]reflect.Value) {
 	// check for implement deepcopy.Interface
 	if original.CanInterface() {
 		if copier, ok := original.Interface().(Interface); ok {
-			cpy.Set(reflect.ValueOf(copier.DeepCopy()))
-			return
+			// a nil or mistyped copy falls back to the reflection copy
+			if c := reflect.ValueOf(copier.DeepCopy()); c.IsValid() && c.Type().AssignableTo(cpy.Type()) {
+				cpy.Set(c)
+				return
+			}
 		}
 	}
 
@@ -57,8 +89,16 @@ func copyRecursive(original, cpy reflect.Value) {
 		if !originalValue.IsValid() {
 			return
 		}
+
+		// if it was already copied, point to the same copy.
+		key := visitKey{ptr: original.Pointer(), typ: original.Type()}
+		if p, ok := visited[key]; ok {
+			cpy.Set(p)
+			return
+		}
 		cpy.Set(reflect.New(originalValue.Type()))
-		copyRecursive(originalValue, cpy.Elem())
+		visited[key] = cpy
+		copyRecursive(originalValue, cpy.Elem(), visited)
 
 	case reflect.Interface:
 		// If this is a nil, don't do anything
@@ -70,13 +110,13 @@ func copyRecursive(original, cpy reflect.Value) {
 
 		// Get the value by calling Elem().
 		copyValue := reflect.New(originalValue.Type()).Elem()
-		copyRecursive(originalValue, copyValue)
+		copyRecursive(originalValue, copyValue, visited)
 		cpy.Set(copyValue)
 
 	case reflect.Struct:
-		t, ok := original.Interface().(time.Time)
-		if ok {
-			cpy.Set(reflect.ValueOf(t))
+		// an opaque type, like time.Time, is copied whole, its unexported fields included
+		if isOpaqueType(original.Type()) {
+			cpy.Set(original)
 			return
 		}
 		// Go through each field of the struct and copy it.
@@ -84,36 +124,70 @@ func copyRecursive(original, cpy reflect.Value) {
 			// The Type's StructField for a given field is checked to see if StructField.PkgPath
 			// is set to determine if the field is exported or not because CanSet() returns false
 			// for settable fields.  I'm not sure why.
-			if original.Type().Field(i).PkgPath != "" {
+			if f := original.Type().Field(i); f.PkgPath != "" && !isEmbeddedStruct(f) {
 				continue
 			}
-			copyRecursive(original.Field(i), cpy.Field(i))
+			copyRecursive(original.Field(i), cpy.Field(i), visited)
 		}
 
 	case reflect.Slice:
 		if original.IsNil() {
 			return
 		}
+		// a slice of interfaces may hold itself, it is copied once
+		key := visitKey{ptr: original.Pointer(), typ: original.Type(), len: original.Len()}
+		holdsIface := original.Type().Elem().Kind() == reflect.Interface
+		if s, ok := visited[key]; ok && holdsIface {
+			cpy.Set(s)
+			return
+		}
 		// Make a new slice and copy each element.
 		cpy.Set(reflect.MakeSlice(original.Type(), original.Len(), original.Cap()))
+		if holdsIface {
+			visited[key] = cpy
+		}
+		for i := 0; i < original.Len(); i++ {
+			copyRecursive(original.Index(i), cpy.Index(i), visited)
+		}
+
+	case reflect.Array:
+		// the items may hold pointers, copy each of them
 		for i := 0; i < original.Len(); i++ {
-			copyRecursive(original.Index(i), cpy.Index(i))
+			copyRecursive(original.Index(i), cpy.Index(i), visited)
 		}
 
 	case reflect.Map:
 		if original.IsNil() {
 			return
 		}
+		// a map of interfaces may hold itself, it is copied once
+		mapKey := visitKey{ptr: original.Pointer(), typ: original.Type()}
+		holdsIface := original.Type().Elem().Kind() == reflect.Interface
+		if m, ok := visited[mapKey]; ok && holdsIface {
+			cpy.Set(m)
+			return
+		}
 		cpy.Set(reflect.MakeMap(original.Type()))
+		if holdsIface {
+			visited[mapKey] = cpy
+		}
 		for _, key := range original.MapKeys() {
 			originalValue := original.MapIndex(key)
 			copyValue := reflect.New(originalValue.Type()).Elem()
-			copyRecursive(originalValue, copyValue)
-			copyKey := Copy(key.Interface())
-			cpy.SetMapIndex(reflect.ValueOf(copyKey), copyValue)
+			copyRecursive(originalValue, copyValue, visited)
+			// the key is copied as a value of the key type, which keeps a nil interface key valid
+			copyKey := reflect.New(key.Type()).Elem()
+			copyRecursive(key, copyKey, visited)
+			cpy.SetMapIndex(copyKey, copyValue)
 		}
 
 	default:
 		cpy.Set(original)
 	}
 }
+
+// isEmbeddedStruct reports whether f is an embedded struct, whose exported fields are promoted
+// and encoded by encoding/json even when the struct type itself is unexported.
+func isEmbeddedStruct(f reflect.StructField) bool {
+	return f.Anonymous && f.Type.Kind() == reflect.Struct
+}
@@ -0,0 +1,132 @@
+package logger
+
+import "testing"
+
+type copyInner struct {
+	Value string
+}
+
+type copyOuter struct {
+	Name  string
+	Ptr   *copyInner
+	Same  *copyInner
+	List  []copyInner
+	Map   map[string]*copyInner
+	Iface interface{}
+}
+
+func newCopyOuter() copyOuter {
+	shared := &copyInner{Value: "shared"}
+	return copyOuter{
+		Name:  "outer",
+		Ptr:   shared,
+		Same:  shared,
+		List:  []copyInner{{Value: "item"}},
+		Map:   map[string]*copyInner{"k": {Value: "map"}},
+		Iface: &copyInner{Value: "iface"},
+	}
+}
+
+func TestCopyDoesNotShareWithOriginal(t *testing.T) {
+	original := newCopyOuter()
+
+	// copy twice, the second copy reusing the pooled state of the first
+	_ = Copy(original)
+	cpy := Copy(original).(copyOuter)
+
+	cpy.Ptr.Value = "changed"
+	cpy.List[0].Value = "changed"
+	cpy.Map["k"].Value = "changed"
+	cpy.Iface.(*copyInner).Value = "changed"
+
+	want := newCopyOuter()
+	if original.Ptr.Value != want.Ptr.Value || original.List[0].Value != want.List[0].Value ||
+		original.Map["k"].Value != want.Map["k"].Value || original.Iface.(*copyInner).Value != "iface" {
+		t.Errorf("original mutated through its copy: %+v", original)
+	}
+	if cpy.Same != cpy.Ptr {
+		t.Error("pointers shared within the original aren't shared within the copy")
+	}
+}
+
+func TestStructEncryptTagDoesNotMutateInput(t *testing.T) {
+	type secretInner struct {
+		Secret string `encrypt:"true"`
+	}
+	type payload struct {
+		Ptr  *secretInner
+		List []secretInner
+		Map  map[string]secretInner
+	}
+	input := payload{
+		Ptr:  &secretInner{"p"},
+		List: []secretInner{{"l"}},
+		Map:  map[string]secretInner{"k": {"m"}},
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if input.Ptr.Secret != "p" || input.List[0].Secret != "l" || input.Map["k"].Secret != "m" {
+		t.Errorf("input mutated: %+v, %+v, %+v", *input.Ptr, input.List, input.Map)
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	type inner struct {
+		S *string
+	}
+	type composite struct {
+		P      *inner
+		L      []inner
+		M      map[string][]int
+		A      [2]*int
+		C      chan int
+		F      func() int
+		hidden int
+	}
+	s, n := "a", 1
+	src := composite{
+		P:      &inner{&s},
+		L:      []inner{{&s}},
+		M:      map[string][]int{"k": {1}},
+		A:      [2]*int{&n},
+		C:      make(chan int),
+		F:      func() int { return 7 },
+		hidden: 3,
+	}
+
+	cpy := DeepCopy(src)
+	*cpy.P.S = "b"
+	*cpy.L[0].S = "c"
+	cpy.M["k"][0] = 9
+	*cpy.A[0] = 5
+
+	if s != "a" || src.M["k"][0] != 1 || n != 1 {
+		t.Errorf("source mutated through its copy: s = %q, m = %v, n = %d", s, src.M, n)
+	}
+	if cpy.C != src.C || cpy.F == nil || cpy.F() != 7 {
+		t.Error("channels and funcs aren't kept as is")
+	}
+	if cpy.hidden != 0 {
+		t.Errorf("unexported field = %d, want it left zero", cpy.hidden)
+	}
+
+	if got := DeepCopy((*composite)(nil)); got != nil {
+		t.Errorf("DeepCopy of a nil pointer = %v, want nil", got)
+	}
+	if got := DeepCopy[error](nil); got != nil {
+		t.Errorf("DeepCopy of a nil interface = %v, want nil", got)
+	}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	input := newCopyOuter()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Copy(input)
+	}
+}
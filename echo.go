@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// EchoLoggerMiddleware returns an Echo middleware logging each request on completion with its method,
+// path, status, latency and the sizes in bytes of the request body read and of the response written,
+// along with the bodies set by SetEchoReqEncrLog and SetEchoRespEncrLog.
+// The sizes are those of the plaintext payloads, whatever the encryption of the logged bodies.
+// The request context carries the trace information read by TraceInfoFromHeader, and its request ID
+// is logged and sent back under the first request ID header set by SetTraceHeaderNames.
+func EchoLoggerMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			traceInfo := TraceInfoFromHeader(c.Request().Header)
+			c.SetRequest(c.Request().WithContext(MergeTraceInfo(c.Request().Context(), traceInfo)))
+			c.Response().Header().Set(GetTraceHeaderNames().RequestID[0], traceInfo.RequestID)
+
+			body := &countingReader{ReadCloser: http.NoBody}
+			if req := c.Request(); req.Body != nil {
+				body.ReadCloser = req.Body
+				req.Body = body
+			}
+
+			err := next(c)
+
+			l := globalLogger()
+			res := c.Response()
+			status := res.Status
+			e := l.Info()
+			if err != nil {
+				// the error handler runs after the middlewares and sets the status from the error
+				if !res.Committed {
+					status = http.StatusInternalServerError
+					var he *echo.HTTPError
+					if errors.As(err, &he) {
+						status = he.Code
+					}
+				}
+				e = l.Error().Err(err)
+			}
+
+			// the setters replace the request to store the bodies in its context
+			req := c.Request()
+			names := GetFieldNames()
+			e = e.Str(KeyMethod, req.Method).
+				Str(KeyPath, req.URL.Path).
+				Str(KeyRequestID, traceInfo.RequestID).
+				Int(KeyStatus, status).
+				Int64(KeyRequestBytes, body.n).
+				Int64(KeyResponseBytes, res.Size).
+				Latency(start)
+			if body, ok := RequestBodyFromContext(req.Context()); ok {
+				e = e.Str(names.RequestBody, body)
+			}
+			if body, ok := ResponseBodyFromContext(req.Context()); ok {
+				e = e.Str(names.ResponseBody, body)
+			}
+			e.Msg("http request")
+
+			return err
+		}
+	}
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+// Read reads from the body and counts the bytes read.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,233 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newEchoContext returns an Echo context for a test request.
+func newEchoContext() echo.Context {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestSetDefaultEncryptTag(t *testing.T) {
+	setTestKey(t)
+	SetDefaultEncryptTag("log", "secret")
+	t.Cleanup(func() { SetDefaultEncryptTag(TagNameEncrypt, TagValEncrypt) })
+
+	type request struct {
+		Card  string `log:"secret"`
+		Other string `encrypt:"true"`
+	}
+	c := newEchoContext()
+	SetEchoReqEncrLog(c, request{Card: "4111", Other: "plain"})
+
+	body, ok := RequestBodyFromContext(c.Request().Context())
+	if !ok {
+		t.Fatal("no request body stored")
+	}
+	var stored request
+	if err := json.Unmarshal([]byte(body), &stored); err != nil {
+		t.Fatal(err)
+	}
+	if mustDecrypt(t, stored.Card) != "4111" {
+		t.Errorf("card = %q, want a ciphertext of the field tagged log:\"secret\"", stored.Card)
+	}
+	if stored.Other != "plain" {
+		t.Errorf("other = %q, want the field with the default tag left as is", stored.Other)
+	}
+}
+
+func TestGetEchoDecrLogRoundTrip(t *testing.T) {
+	setTestKey(t)
+
+	type body struct {
+		Card string `encrypt:"true"`
+		Name string
+	}
+	type response struct {
+		Code int
+		Data body
+	}
+	c := newEchoContext()
+	SetEchoReqEncrLog(c, body{Card: "4111", Name: "bob"})
+	SetEchoRespEncrLog(c, response{Code: 200, Data: body{Card: "5500", Name: "alice"}})
+
+	req, err := GetEchoReqDecrLog(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req != `{"Card":"4111","Name":"bob"}` {
+		t.Errorf("request = %s, want the plaintext body", req)
+	}
+
+	resp, err := GetEchoRespDecrLog(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != `{"Card":"5500","Name":"alice"}` {
+		t.Errorf("response = %s, want the plaintext data", resp)
+	}
+}
+
+func TestSetEchoRespEncrLogDataField(t *testing.T) {
+	setTestKey(t)
+	t.Cleanup(func() {
+		SetResponseDataField("")
+		EncryptWholeResponse(false)
+	})
+
+	type card struct {
+		PAN string `encrypt:"true"`
+	}
+	type envelope struct {
+		Code int
+		Data *card
+	}
+	type flat struct {
+		PAN string `encrypt:"true"`
+		OK  bool
+	}
+	type result struct {
+		Result card
+	}
+
+	tests := []struct {
+		name  string
+		field string
+		whole bool
+		resp  interface{}
+		want  string
+	}{
+		{"envelope", "", false, envelope{Code: 200, Data: &card{PAN: "4111"}}, `{"PAN":"4111"}`},
+		{"no envelope", "", false, flat{PAN: "4111", OK: true}, ""},
+		{"whole response", "", true, &flat{PAN: "4111", OK: true}, `{"OK":true,"PAN":"4111"}`},
+		{"whole envelope", "", true, envelope{Code: 200, Data: &card{PAN: "4111"}}, `{"PAN":"4111"}`},
+		{"custom field", "Result", false, result{Result: card{PAN: "4111"}}, `{"PAN":"4111"}`},
+		{"custom field absent", "Result", false, envelope{Code: 200, Data: &card{PAN: "4111"}}, ""},
+	}
+	for _, tt := range tests {
+		SetResponseDataField(tt.field)
+		EncryptWholeResponse(tt.whole)
+		c := newEchoContext()
+
+		SetEchoRespEncrLog(c, tt.resp)
+
+		stored, _ := ResponseBodyFromContext(c.Request().Context())
+		if strings.Contains(stored, "4111") {
+			t.Errorf("%s: stored %s, want the PAN encrypted", tt.name, stored)
+		}
+		resp, err := GetEchoRespDecrLog(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp != tt.want {
+			t.Errorf("%s: response = %q, want %q", tt.name, resp, tt.want)
+		}
+	}
+}
+
+func TestGetEchoDecrLogNothingStored(t *testing.T) {
+	c := newEchoContext()
+
+	for _, get := range []func(echo.Context) (string, error){GetEchoReqDecrLog, GetEchoRespDecrLog} {
+		if body, err := get(c); body != "" || err != nil {
+			t.Errorf("got %q, %v, want an empty string and no error", body, err)
+		}
+	}
+}
+
+func TestEchoSetterContextCanceled(t *testing.T) {
+	setTestKey(t)
+	c := newEchoContext()
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	cancel()
+	c.SetRequest(c.Request().WithContext(ctx))
+
+	SetEchoReqEncrLog(c, treeNode{Secret: "s"})
+
+	if body, ok := RequestBodyFromContext(c.Request().Context()); ok {
+		t.Errorf("stored %q, want nothing stored once the request is canceled", body)
+	}
+}
+
+func TestEchoLoggerMiddleware(t *testing.T) {
+	setTestKey(t)
+	l, buf := newTestLogger()
+	setGlobalLogger(t, l)
+
+	type payment struct {
+		Card string `encrypt:"true"`
+	}
+	e := echo.New()
+	e.Use(EchoLoggerMiddleware())
+	e.POST("/pay", func(c echo.Context) error {
+		b, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		SetEchoReqEncrLog(c, payment{Card: string(b)})
+		return c.String(http.StatusCreated, "0123456789")
+	})
+	e.GET("/teapot", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot, "no")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/pay", strings.NewReader(strings.Repeat("4", 42)))
+	req.Header.Set("X-Request-ID", "rid-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if got := rec.Header().Get("X-Request-ID"); got != "rid-1" {
+		t.Errorf("request ID header = %q, want rid-1 sent back", got)
+	}
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/teapot", nil))
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	pay, teapot := entries[0], entries[1]
+	// the sizes are those of the plaintexts, the logged body being encrypted
+	if pay[KeyRequestBytes] != float64(42) || pay[KeyResponseBytes] != float64(10) {
+		t.Errorf("sizes = %v and %v, want 42 and 10", pay[KeyRequestBytes], pay[KeyResponseBytes])
+	}
+	if pay[KeyStatus] != float64(http.StatusCreated) || pay[KeyPath] != "/pay" || pay[KeyRequestID] != "rid-1" {
+		t.Errorf("pay entry = %v, want the status, path and request ID", pay)
+	}
+	if body, _ := pay[KeyRequestBody].(string); body == "" || strings.Contains(body, strings.Repeat("4", 42)) {
+		t.Errorf("request body = %q, want it logged encrypted", body)
+	}
+	if teapot["level"] != "error" || teapot[KeyStatus] != float64(http.StatusTeapot) || teapot[KeyRequestBytes] != float64(0) {
+		t.Errorf("teapot entry = %v, want the status of the error and no request bytes", teapot)
+	}
+}
+
+func TestEchoLoggerMiddlewareGeneratedRequestID(t *testing.T) {
+	l, buf := newTestLogger()
+	setGlobalLogger(t, l)
+
+	e := echo.New()
+	e.Use(EchoLoggerMiddleware())
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	sent := rec.Header().Get(HeaderRequestID)
+	if sent == "" {
+		t.Fatal("no request ID sent back without a request ID header")
+	}
+	if entry := decodeLines(t, buf)[0]; entry[KeyRequestID] != sent {
+		t.Errorf("logged request ID %v, want the one sent back %s", entry[KeyRequestID], sent)
+	}
+}
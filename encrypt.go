@@ -1,144 +1,202 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 )
 
+// recoverWalk converts a panic raised while walking input into an error,
+// so a malformed input degrades to an error rather than crashing the caller.
+func recoverWalk[T any](op string, input T, res *T, err *error) {
+	if r := recover(); r != nil {
+		*res = input
+		if op == "encrypt" {
+			*res, _ = abortedEncrypt(input, nil)
+		}
+		*err = fmt.Errorf("%s: %v", op, r)
+	}
+}
+
+// abortedEncrypt returns what the encrypt functions return for input when the walk fails with err:
+// input itself with EncryptFailureReturnOriginal, and otherwise the zero value, so that no plaintext
+// is returned.
+func abortedEncrypt[T any](input T, err error) (T, error) {
+	if EncryptFailurePolicy(encryptFailurePolicy.Load()) == EncryptFailureReturnOriginal {
+		return input, err
+	}
+
+	var zero T
+	return zero, err
+}
+
+// isNilInput reports whether input is nil or a nil pointer, map, slice or interface,
+// which the encrypt and decrypt functions return as is.
+func isNilInput(input interface{}) bool {
+	return input == nil || isNilValue(reflect.ValueOf(input))
+}
+
 // StructEncryptTag encrypts fields of a struct based on the tag `tagName:"tagVal"`.
 // It returns a new struct with encrypted fields or an error if encryption fails.
+// Tagged number fields are an error, since they can't hold their ciphertext.
+// Fields tagged `json:"-"` are left as is, the JSON encoding of the log omitting them.
+// A nil input, including a nil pointer, is returned as is, as by every encrypt and decrypt function.
+// A field failing to encrypt is handled as set by SetEncryptFailurePolicy.
 func StructEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
-		return input, nil
-	}
+	return StructEncryptTagContext(context.Background(), input, key, tagName, tagVal)
+}
 
-	// deep copy input
-	inputCopy := Copy(input)
+// StructEncryptTagContext is StructEncryptTag aborting with ctx.Err() once ctx is done,
+// checked before walking each nested struct.
+func StructEncryptTagContext[T any](ctx context.Context, input T, key, tagName, tagVal string) (res T, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
 
-	v := reflect.ValueOf(inputCopy)
+	if key == "" || isNilInput(input) || encryptionDisabled.Load() {
+		return input, nil
+	}
 
-	var isPtr bool
-	if v.Type().Kind() == reflect.Ptr {
-		v = v.Elem()
-		isPtr = true
+	w := newTagWalker(key, tagName, tagVal, false)
+	w.ctx = ctx
+	// the copy comes with an error when the failure policy blanked a field
+	output, err := w.copyStruct(input)
+	if output == nil {
+		return abortedEncrypt(input, err)
 	}
 
-	t := v.Type()
+	return output.(T), err
+}
 
-	// check if input is a struct
-	if t.Kind() != reflect.Struct {
-		return input, fmt.Errorf("input is not a struct")
-	}
+// StructEncryptByName is EncryptInterface also encrypting the untagged string fields whose names
+// match a pattern set by SetAutoEncryptFieldPatterns, e.g. for structs that can't be tagged.
+// The input is returned unchanged when it isn't a struct, a pointer to struct or a slice.
+func StructEncryptByName(input interface{}) (res interface{}, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
 
-	output := reflect.New(t).Elem()
+	if keyEncrypt == nil || *keyEncrypt == "" || isNilInput(input) || encryptionDisabled.Load() {
+		return input, nil
+	}
 
-	// Copy the values from input to output
-	output.Set(v)
+	tagName, tagVal := defaultEncryptTag()
+	w := newTagWalker(*keyEncrypt, tagName, tagVal, false)
+	w.match = matchAutoEncryptField
 
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
+	v := reflect.ValueOf(input)
+	switch {
+	case v.Kind() == reflect.Slice:
+		res, err = w.copySlice(input)
+	case isStructElem(v.Type()):
+		res, err = w.copyStruct(input)
+	default:
+		return input, nil
+	}
+	if res == nil {
+		return abortedEncrypt(input, err)
+	}
 
-		// check field type is time.Time
-		if field.Kind() == reflect.Struct && field.Type().String() == "time.Time" {
-			continue
-		}
+	return res, err
+}
 
-		// check field type is *time.Time
-		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct && field.Elem().Type().String() == "time.Time" {
-			continue
-		}
+// StructEncryptTagExcept is StructEncryptTag leaving the fields at the paths of exclude in plaintext.
+// A path is the dot-separated names of the fields from input, e.g. "Card" or "Billing.Card",
+// the fields promoted from embedded structs being named as in the outer struct.
+func StructEncryptTagExcept[T any](input T, key, tagName, tagVal string, exclude ...string) (res T, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
 
-		tag := t.Field(i).Tag.Get(tagName)
+	if key == "" || isNilInput(input) || encryptionDisabled.Load() {
+		return input, nil
+	}
 
-		if tag == tagVal && field.Kind() == reflect.String {
-			encryptedValue, err := Encrypt(field.String(), key)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).SetString(encryptedValue)
-			continue
-		}
+	w := newTagWalker(key, tagName, tagVal, false)
+	w.exclude = make(map[string]bool, len(exclude))
+	for _, path := range exclude {
+		w.exclude[path] = true
+	}
+	output, err := w.copyStruct(input)
+	if output == nil {
+		return abortedEncrypt(input, err)
+	}
 
-		if tag == tagVal && (field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.String) {
-			encryptedValue, err := Encrypt(field.Elem().String(), key)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).Elem().Set(reflect.ValueOf(encryptedValue))
-			continue
-		}
+	return output.(T), err
+}
 
-		if field.Kind() == reflect.Struct {
-			encryptedField, err := StructEncryptTag(field.Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).Set(reflect.ValueOf(encryptedField))
-			continue
-		}
+// StructEncryptTags is StructEncryptTag encrypting the fields whose tagName tag has any of tagVals,
+// e.g. both `log:"pii"` and `log:"secret"` in one walk.
+func StructEncryptTags[T any](input T, key, tagName string, tagVals ...string) (res T, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
 
-		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct {
-			encryptedField, err := StructEncryptTag(field.Elem().Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).Elem().Set(reflect.ValueOf(encryptedField))
-		}
+	if key == "" || len(tagVals) == 0 || isNilInput(input) || encryptionDisabled.Load() {
+		return input, nil
 	}
 
-	if isPtr {
-		return output.Addr().Interface().(T), nil
+	w := newTagWalker(key, tagName, tagVals[0], false)
+	w.tagVals = tagVals[1:]
+	output, err := w.copyStruct(input)
+	if output == nil {
+		return abortedEncrypt(input, err)
 	}
 
-	return output.Interface().(T), nil
+	return output.(T), err
 }
 
 // StructSliceEncryptTag encrypts fields of a slice of struct based on the tag `tagName:"tagVal"`.
 // It returns a new slice with encrypted fields or an error if encryption fails.
 func StructSliceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
+	return StructSliceEncryptTagContext(context.Background(), input, key, tagName, tagVal)
+}
+
+// StructSliceEncryptTagContext is StructSliceEncryptTag aborting with ctx.Err() once ctx is done,
+// checked before walking each struct item.
+func StructSliceEncryptTagContext[T any](ctx context.Context, input T, key, tagName, tagVal string) (res T, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
+
+	if key == "" || isNilInput(input) || encryptionDisabled.Load() {
 		return input, nil
 	}
 
-	// deep copy input
-	inputCopy := Copy(input)
+	w := newTagWalker(key, tagName, tagVal, false)
+	w.ctx = ctx
+	output, err := w.copySlice(input)
+	if output == nil {
+		return abortedEncrypt(input, err)
+	}
+
+	return output.(T), err
+}
 
-	v := reflect.ValueOf(inputCopy)
+// StructSliceEncryptTagFunc is StructSliceEncryptTag encrypting only the items for which include,
+// called with the index and a copy of each item, returns true. A nil include selects every item.
+func StructSliceEncryptTagFunc[T any](input T, key, tagName, tagVal string, include func(index int, item interface{}) bool) (res T, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
+
+	if key == "" || isNilInput(input) || encryptionDisabled.Load() {
+		return input, nil
+	}
 
+	v := copyValue(input)
 	if v.Kind() != reflect.Slice {
-		return input, fmt.Errorf("input is not a slice")
+		return abortedEncrypt(input, ErrNotSlice)
 	}
 
+	w := newTagWalker(key, tagName, tagVal, false)
 	for i := 0; i < v.Len(); i++ {
-		item := v.Index(i)
-
-		// check if item is a struct
-		if item.Kind() == reflect.Struct {
-			encryptedItem, err := StructEncryptTag(item.Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			v.Index(i).Set(reflect.ValueOf(encryptedItem))
+		if include != nil && !include(i, v.Index(i).Interface()) {
 			continue
 		}
-
-		// check if item is a pointer struct
-		if item.Kind() == reflect.Ptr && item.Elem().Kind() == reflect.Struct {
-			encryptedItem, err := StructEncryptTag(item.Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			v.Index(i).Set(reflect.ValueOf(encryptedItem))
+		// the one item slice shares the items of v, walked in place
+		if err := w.walkSlice(v.Slice(i, i+1), 0); err != nil {
+			return abortedEncrypt(input, err)
 		}
 	}
 
-	return v.Interface().(T), nil
+	return v.Interface().(T), w.blankedErr()
 }
 
 // InterfaceEncryptTag encrypts fields of a struct, pointer to struct, or slice based on the tag `tagName:"tagVal"`.
 // It returns a new value with encrypted fields or an error if encryption fails.
-func InterfaceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
+func InterfaceEncryptTag[T any](input T, key, tagName, tagVal string) (res T, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
+
+	if key == "" || isNilInput(input) || encryptionDisabled.Load() {
 		return input, nil
 	}
 
@@ -146,29 +204,23 @@ func InterfaceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 
 	// check if input is a struct
 	if v.Kind() == reflect.Struct {
-		if result, err := StructEncryptTag(v.Interface(), key, tagName, tagVal); err != nil {
-			return input, err
-		} else {
-			return result.(T), nil
-		}
+		result, err := StructEncryptTag(v.Interface(), key, tagName, tagVal)
+		res, _ = result.(T)
+		return res, err
 	}
 
 	// check if item is a pointer struct
 	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
-		if result, err := StructEncryptTag(v.Interface(), key, tagName, tagVal); err != nil {
-			return input, err
-		} else {
-			return result.(T), nil
-		}
+		result, err := StructEncryptTag(v.Interface(), key, tagName, tagVal)
+		res, _ = result.(T)
+		return res, err
 	}
 
 	// check if input is a slice
 	if v.Kind() == reflect.Slice {
-		if result, err := StructSliceEncryptTag(v.Interface(), key, tagName, tagVal); err != nil {
-			return input, err
-		} else {
-			return result.(T), nil
-		}
+		result, err := StructSliceEncryptTag(v.Interface(), key, tagName, tagVal)
+		res, _ = result.(T)
+		return res, err
 	}
 
 	return input, nil
@@ -176,138 +228,44 @@ func InterfaceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 
 // StructDecryptTag decrypts fields of a struct based on the tag `tagName:"tagVal"`.
 // It returns a new struct with decrypted fields or an error if decryption fails.
-func StructDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
-		return input, nil
-	}
+func StructDecryptTag[T any](input T, key, tagName, tagVal string) (res T, err error) {
+	defer recoverWalk("decrypt", input, &res, &err)
 
-	// deep copy input
-	inputCopy := Copy(input)
-
-	v := reflect.ValueOf(inputCopy)
-
-	var isPtr bool
-	if v.Type().Kind() == reflect.Ptr {
-		v = v.Elem()
-		isPtr = true
-	}
-
-	t := v.Type()
-
-	// check if input is a struct
-	if t.Kind() != reflect.Struct {
-		return input, fmt.Errorf("input is not a struct")
-	}
-
-	output := reflect.New(t).Elem()
-
-	// Copy the values from input to output
-	output.Set(v)
-
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-
-		// check field type is time.Time
-		if field.Kind() == reflect.Struct && field.Type().String() == "time.Time" {
-			continue
-		}
-
-		// check field type is *time.Time
-		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct && field.Elem().Type().String() == "time.Time" {
-			continue
-		}
-
-		tag := t.Field(i).Tag.Get(tagName)
-
-		if tag == tagVal && field.Kind() == reflect.String {
-			encryptedValue, err := Decrypt(field.String(), key)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).SetString(encryptedValue)
-			continue
-		}
-
-		if tag == tagVal && (field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.String) {
-			encryptedValue, err := Decrypt(field.Elem().String(), key)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).Elem().Set(reflect.ValueOf(encryptedValue))
-			continue
-		}
-
-		if field.Kind() == reflect.Struct {
-			encryptedField, err := StructDecryptTag(field.Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).Set(reflect.ValueOf(encryptedField))
-			continue
-		}
-
-		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct {
-			encryptedField, err := StructDecryptTag(field.Elem().Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).Elem().Set(reflect.ValueOf(encryptedField))
-		}
+	if key == "" || isNilInput(input) {
+		return input, nil
 	}
 
-	if isPtr {
-		return output.Addr().Interface().(T), nil
+	output, err := newTagWalker(key, tagName, tagVal, true).copyStruct(input)
+	if err != nil {
+		return input, err
 	}
 
-	return output.Interface().(T), nil
+	return output.(T), nil
 }
 
 // StructSliceDecryptTag decrypts fields of a slice of struct based on the tag `tagName:"tagVal"`.
 // It returns a new slice with decrypted fields or an error if decryption fails.
-func StructSliceDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
-		return input, nil
-	}
-
-	// deep copy input
-	inputCopy := Copy(input)
-
-	v := reflect.ValueOf(inputCopy)
+func StructSliceDecryptTag[T any](input T, key, tagName, tagVal string) (res T, err error) {
+	defer recoverWalk("decrypt", input, &res, &err)
 
-	if v.Kind() != reflect.Slice {
-		return input, fmt.Errorf("input is not a slice")
+	if key == "" || isNilInput(input) {
+		return input, nil
 	}
 
-	for i := 0; i < v.Len(); i++ {
-		item := v.Index(i)
-
-		// check if item is a struct
-		if item.Kind() == reflect.Struct {
-			encryptedItem, err := StructDecryptTag(item.Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			v.Index(i).Set(reflect.ValueOf(encryptedItem))
-			continue
-		}
-
-		// check if item is a pointer struct
-		if item.Kind() == reflect.Ptr && item.Elem().Kind() == reflect.Struct {
-			encryptedItem, err := StructDecryptTag(item.Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			v.Index(i).Set(reflect.ValueOf(encryptedItem))
-		}
+	output, err := newTagWalker(key, tagName, tagVal, true).copySlice(input)
+	if err != nil {
+		return input, err
 	}
 
-	return v.Interface().(T), nil
+	return output.(T), nil
 }
 
 // InterfaceDecryptTag decrypts fields of a struct, pointer to struct, or slice based on the tag `tagName:"tagVal"`.
 // It returns a new value with decrypted fields or an error if decryption fails.
-func InterfaceDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
+func InterfaceDecryptTag[T any](input T, key, tagName, tagVal string) (res T, err error) {
+	defer recoverWalk("decrypt", input, &res, &err)
+
+	if key == "" || isNilInput(input) {
 		return input, nil
 	}
 
@@ -345,138 +303,49 @@ func InterfaceDecryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 
 // StructEncryptTagInterface encrypts fields of a struct (interface{}) based on the tag `tagName:"tagVal"`.
 // It returns a new struct with encrypted fields or an error if encryption fails.
-func StructEncryptTagInterface(input interface{}, key, tagName, tagVal string) (interface{}, error) {
-	if key == "" {
-		return input, nil
-	}
-
-	// deep copy input
-	inputCopy := Copy(input)
-
-	v := reflect.ValueOf(inputCopy)
-
-	var isPtr bool
-	if v.Type().Kind() == reflect.Ptr {
-		v = v.Elem()
-		isPtr = true
-	}
-
-	t := v.Type()
-
-	// check if input is a struct
-	if t.Kind() != reflect.Struct {
-		return input, fmt.Errorf("input is not a struct")
-	}
-
-	output := reflect.New(t).Elem()
-
-	// Copy the values from input to output
-	output.Set(v)
-
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-
-		// check field type is time.Time
-		if field.Kind() == reflect.Struct && field.Type().String() == "time.Time" {
-			continue
-		}
-
-		// check field type is *time.Time
-		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct && field.Elem().Type().String() == "time.Time" {
-			continue
-		}
-
-		tag := t.Field(i).Tag.Get(tagName)
-
-		if tag == tagVal && field.Kind() == reflect.String {
-			encryptedValue, err := Encrypt(field.String(), key)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).SetString(encryptedValue)
-			continue
-		}
-
-		if tag == tagVal && (field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.String) {
-			encryptedValue, err := Encrypt(field.Elem().String(), key)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).Elem().Set(reflect.ValueOf(encryptedValue))
-			continue
-		}
-
-		if field.Kind() == reflect.Struct {
-			encryptedField, err := StructEncryptTagInterface(field.Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).Set(reflect.ValueOf(encryptedField))
-			continue
-		}
+func StructEncryptTagInterface(input interface{}, key, tagName, tagVal string) (res interface{}, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
 
-		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct {
-			encryptedField, err := StructEncryptTagInterface(field.Elem().Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			output.Field(i).Elem().Set(reflect.ValueOf(encryptedField))
-		}
+	if key == "" || isNilInput(input) || encryptionDisabled.Load() {
+		return input, nil
 	}
 
-	if isPtr {
-		return output.Addr().Interface(), nil
+	output, err := newTagWalker(key, tagName, tagVal, false).copyStruct(input)
+	if output == nil {
+		return abortedEncrypt(input, err)
 	}
 
-	return output.Interface(), nil
+	return output, err
 }
 
 // StructSliceEncryptTagInterface encrypts fields of a slice of struct (interface{}) based on the tag `tagName:"tagVal"`.
 // It returns a new slice with encrypted fields or an error if encryption fails.
-func StructSliceEncryptTagInterface(input interface{}, key, tagName, tagVal string) (interface{}, error) {
-	if key == "" {
-		return input, nil
-	}
-
-	// deep copy input
-	inputCopy := Copy(input)
+func StructSliceEncryptTagInterface(input interface{}, key, tagName, tagVal string) (res interface{}, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
 
-	v := reflect.ValueOf(inputCopy)
-
-	if v.Kind() != reflect.Slice {
-		return input, fmt.Errorf("input is not a slice")
+	if key == "" || isNilInput(input) || encryptionDisabled.Load() {
+		return input, nil
 	}
 
-	for i := 0; i < v.Len(); i++ {
-		item := v.Index(i)
-
-		// check if item is a struct
-		if item.Kind() == reflect.Struct {
-			encryptedItem, err := StructEncryptTag(item.Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			v.Index(i).Set(reflect.ValueOf(encryptedItem))
-			continue
-		}
-
-		// check if item is a pointer struct
-		if item.Kind() == reflect.Ptr && item.Elem().Kind() == reflect.Struct {
-			encryptedItem, err := StructEncryptTag(item.Interface(), key, tagName, tagVal)
-			if err != nil {
-				return input, err
-			}
-			v.Index(i).Set(reflect.ValueOf(encryptedItem))
-		}
+	output, err := newTagWalker(key, tagName, tagVal, false).copySlice(input)
+	if output == nil {
+		return abortedEncrypt(input, err)
 	}
 
-	return v.Interface(), nil
+	return output, err
 }
 
 // InterfaceEncryptTagInterface encrypts fields of a struct, pointer to struct, or slice (interface{}) based on the tag `tagName:"tagVal"`.
 // It returns a new value with encrypted fields or an error if encryption fails.
 func InterfaceEncryptTagInterface(input interface{}, key, tagName, tagVal string) (interface{}, error) {
-	if key == "" {
+	return InterfaceEncryptTagContext(context.Background(), input, key, tagName, tagVal)
+}
+
+// InterfaceEncryptTagContext is InterfaceEncryptTagInterface aborting with ctx.Err() once ctx is done.
+func InterfaceEncryptTagContext(ctx context.Context, input interface{}, key, tagName, tagVal string) (res interface{}, err error) {
+	defer recoverWalk("encrypt", input, &res, &err)
+
+	if key == "" || isNilInput(input) || encryptionDisabled.Load() {
 		return input, nil
 	}
 
@@ -484,30 +353,57 @@ func InterfaceEncryptTagInterface(input interface{}, key, tagName, tagVal string
 
 	// check if input is a struct
 	if v.Kind() == reflect.Struct {
-		if result, err := StructEncryptTagInterface(v.Interface(), key, tagName, tagVal); err != nil {
-			return input, err
-		} else {
-			return result, nil
-		}
+		return StructEncryptTagContext(ctx, v.Interface(), key, tagName, tagVal)
 	}
 
 	// check if item is a pointer struct
 	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
-		if result, err := StructEncryptTagInterface(v.Interface(), key, tagName, tagVal); err != nil {
-			return input, err
-		} else {
-			return result, nil
-		}
+		return StructEncryptTagContext(ctx, v.Interface(), key, tagName, tagVal)
 	}
 
 	// check if input is a slice
 	if v.Kind() == reflect.Slice {
-		if result, err := StructSliceEncryptTagInterface(v.Interface(), key, tagName, tagVal); err != nil {
+		return StructSliceEncryptTagContext(ctx, v.Interface(), key, tagName, tagVal)
+	}
+
+	return input, nil
+}
+
+// StringSliceEncrypt encrypts each element of a string slice.
+// It returns a new slice with encrypted elements, or an error if encryption fails along with nil,
+// or input under EncryptFailureReturnOriginal.
+func StringSliceEncrypt(input []string, key string) ([]string, error) {
+	if key == "" || input == nil || encryptionDisabled.Load() {
+		return input, nil
+	}
+
+	output := make([]string, len(input))
+	for i, item := range input {
+		encryptedItem, err := Encrypt(item, key)
+		if err != nil {
+			return abortedEncrypt(input, err)
+		}
+		output[i] = encryptedItem
+	}
+
+	return output, nil
+}
+
+// StringSliceDecrypt decrypts each element of a string slice.
+// It returns a new slice with decrypted elements or an error if decryption fails.
+func StringSliceDecrypt(input []string, key string) ([]string, error) {
+	if key == "" || input == nil {
+		return input, nil
+	}
+
+	output := make([]string, len(input))
+	for i, item := range input {
+		decryptedItem, err := Decrypt(item, key)
+		if err != nil {
 			return input, err
-		} else {
-			return result, nil
 		}
+		output[i] = decryptedItem
 	}
 
-	return input, nil
+	return output, nil
 }
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"strconv"
+	"testing"
+)
+
+// benchKey is a 16 bytes AES key, the walkers running the real Encrypt.
+const benchKey = "000102030405060708090a0b0c0d0e0f"
+
+type benchAddress struct {
+	Street string `encrypt:"true"`
+	City   string
+	Zip    string `encrypt:"true"`
+}
+
+type benchFlat struct {
+	ID    int
+	Name  string
+	Email string `encrypt:"true"`
+	Phone string `encrypt:"true"`
+	Note  string
+}
+
+type benchNested struct {
+	ID       int
+	Owner    benchFlat
+	Billing  *benchAddress
+	Shipping benchAddress
+	Tags     []string `encrypt:"true"`
+	Items    []benchFlat
+}
+
+func newBenchFlat(i int) benchFlat {
+	n := strconv.Itoa(i)
+	return benchFlat{ID: i, Name: "name" + n, Email: "user" + n + "@example.com", Phone: "+1555" + n, Note: "note"}
+}
+
+func newBenchNested() benchNested {
+	return benchNested{
+		ID:       1,
+		Owner:    newBenchFlat(0),
+		Billing:  &benchAddress{Street: "1 Main St", City: "Springfield", Zip: "12345"},
+		Shipping: benchAddress{Street: "2 Side St", City: "Shelbyville", Zip: "54321"},
+		Tags:     []string{"a", "b", "c"},
+		Items:    []benchFlat{newBenchFlat(1), newBenchFlat(2), newBenchFlat(3)},
+	}
+}
+
+func newBenchSlice(n int) []benchFlat {
+	items := make([]benchFlat, n)
+	for i := range items {
+		items[i] = newBenchFlat(i)
+	}
+	return items
+}
+
+func BenchmarkStructEncryptTagFlat(b *testing.B) {
+	input := newBenchFlat(1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := StructEncryptTag(input, benchKey, TagNameEncrypt, TagValEncrypt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStructEncryptTagNested(b *testing.B) {
+	input := newBenchNested()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := StructEncryptTag(&input, benchKey, TagNameEncrypt, TagValEncrypt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStructSliceEncryptTagLarge(b *testing.B) {
+	input := newBenchSlice(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := StructSliceEncryptTag(input, benchKey, TagNameEncrypt, TagValEncrypt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInterfaceEncryptTagFlat(b *testing.B) {
+	input := newBenchFlat(1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := InterfaceEncryptTag(input, benchKey, TagNameEncrypt, TagValEncrypt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInterfaceEncryptTagNested(b *testing.B) {
+	input := newBenchNested()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := InterfaceEncryptTag(&input, benchKey, TagNameEncrypt, TagValEncrypt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInterfaceEncryptTagLarge(b *testing.B) {
+	input := newBenchSlice(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := InterfaceEncryptTag(input, benchKey, TagNameEncrypt, TagValEncrypt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// shapeSource reads the choices of the shape generator from the fuzzer's bytes, then zeros.
+type shapeSource struct {
+	data []byte
+	i    int
+}
+
+func (s *shapeSource) next() int {
+	if s.i >= len(s.data) {
+		return 0
+	}
+	s.i++
+	return int(s.data[s.i-1])
+}
+
+var (
+	stringType = reflect.TypeOf("")
+	ifaceType  = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// genType returns a random type built from strings, ints, structs, pointers, slices, arrays,
+// maps and interfaces, the leaves only past depth 3.
+func genType(s *shapeSource, depth int) reflect.Type {
+	k := s.next() % 14
+	if depth > 3 {
+		k %= 4
+	}
+	switch k {
+	case 0:
+		return stringType
+	case 1:
+		return reflect.PointerTo(stringType)
+	case 2:
+		return reflect.SliceOf(stringType)
+	case 3:
+		return reflect.TypeOf(0)
+	case 4:
+		return genStruct(s, depth+1)
+	case 5:
+		return reflect.PointerTo(genStruct(s, depth+1))
+	case 6:
+		return reflect.SliceOf(genStruct(s, depth+1))
+	case 7:
+		return reflect.SliceOf(reflect.PointerTo(genStruct(s, depth+1)))
+	case 8:
+		return reflect.MapOf(stringType, genType(s, depth+1))
+	case 9:
+		return ifaceType
+	case 10:
+		return reflect.PointerTo(reflect.SliceOf(genStruct(s, depth+1)))
+	case 11:
+		return reflect.ArrayOf(2, genType(s, depth+1))
+	case 12:
+		return reflect.SliceOf(reflect.PointerTo(stringType))
+	default:
+		return reflect.PointerTo(reflect.PointerTo(genStruct(s, depth+1)))
+	}
+}
+
+// genStruct returns a struct type of 1 to 4 fields, a third of them tagged for encryption.
+// The int fields are never tagged, as a tagged number is an error.
+func genStruct(s *shapeSource, depth int) reflect.Type {
+	n := s.next()%4 + 1
+	fields := make([]reflect.StructField, n)
+	for i := range fields {
+		fields[i] = reflect.StructField{Name: fmt.Sprintf("F%d", i), Type: genType(s, depth)}
+		if s.next()%3 == 0 && fields[i].Type.Kind() != reflect.Int {
+			fields[i].Tag = `encrypt:"true"`
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+// fill sets v to random values, leaving about a quarter of the pointers, slices and maps nil.
+func fill(s *shapeSource, v reflect.Value, depth int) {
+	if depth > 6 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(fmt.Sprintf("s%d", s.next()))
+	case reflect.Int:
+		v.SetInt(int64(s.next()))
+	case reflect.Ptr:
+		if s.next()%4 == 0 {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		fill(s, p.Elem(), depth+1)
+		v.Set(p)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fill(s, v.Field(i), depth+1)
+		}
+	case reflect.Slice:
+		if s.next()%4 == 0 {
+			return
+		}
+		n := s.next() % 3
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			fill(s, v.Index(i), depth+1)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fill(s, v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if s.next()%4 == 0 {
+			return
+		}
+		v.Set(reflect.MakeMap(v.Type()))
+		for i := 0; i < s.next()%3; i++ {
+			e := reflect.New(v.Type().Elem()).Elem()
+			fill(s, e, depth+1)
+			v.SetMapIndex(reflect.ValueOf(fmt.Sprint(i)), e)
+		}
+	case reflect.Interface:
+		switch s.next() % 4 {
+		case 1:
+			v.Set(reflect.ValueOf("x"))
+		case 2:
+			st := reflect.New(genStruct(s, 5)).Elem()
+			fill(s, st, depth+1)
+			v.Set(st)
+		case 3:
+			st := reflect.New(genStruct(s, 5))
+			fill(s, st.Elem(), depth+1)
+			v.Set(st)
+		}
+	}
+}
+
+func FuzzStructEncryptTag(f *testing.F) {
+	f.Add([]byte{4, 1, 2, 3})
+	f.Add([]byte{9, 9, 9, 9, 9, 9, 9, 9, 2, 3, 2, 3})
+	f.Add([]byte{8, 9, 2, 0, 0, 7, 1, 4, 0, 11, 13, 6, 5, 10})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		s := &shapeSource{data: data}
+		v := reflect.New(genStruct(s, 0)).Elem()
+		fill(s, v, 0)
+		input := v.Interface()
+
+		encrypted, err := InterfaceEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+		if err != nil {
+			t.Fatalf("encrypt %#v: %v", input, err)
+		}
+		decrypted, err := InterfaceDecryptTag(encrypted, testKey, TagNameEncrypt, TagValEncrypt)
+		if err != nil {
+			t.Fatalf("decrypt %#v: %v", encrypted, err)
+		}
+		if !reflect.DeepEqual(decrypted, input) {
+			t.Fatalf("encrypt then decrypt isn't identity:\n%#v\n%#v", input, decrypted)
+		}
+
+		// through a pointer and a slice too
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		if _, err := InterfaceEncryptTag(p.Interface(), testKey, TagNameEncrypt, TagValEncrypt); err != nil {
+			t.Fatalf("encrypt pointer: %v", err)
+		}
+		sl := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
+		sl.Index(0).Set(v)
+		if _, err := InterfaceEncryptTag(sl.Interface(), testKey, TagNameEncrypt, TagValEncrypt); err != nil {
+			t.Fatalf("encrypt slice: %v", err)
+		}
+	})
+}
+
+// nilCopier returns a nil copy, which Copy must not set into a typed value.
+type nilCopier struct {
+	Secret string `encrypt:"true"`
+}
+
+func (nilCopier) DeepCopy() interface{} { return nil }
+
+// the cases found by FuzzStructEncryptTag, once panicking or hanging
+func TestStructEncryptTagFuzzRegressions(t *testing.T) {
+	selfMap := map[string]interface{}{"k": "v"}
+	selfMap["self"] = selfMap
+	selfSlice := []interface{}{"v", nil}
+	selfSlice[1] = selfSlice
+
+	tests := []struct {
+		name  string
+		input interface{}
+	}{
+		{"nil interface map key", map[interface{}]string{nil: "x", "k": "y"}},
+		{"nil DeepCopy result", struct{ C nilCopier }{nilCopier{"s"}}},
+		{"map holding itself", struct{ M map[string]interface{} }{selfMap}},
+		{"slice holding itself", struct{ S []interface{} }{selfSlice}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := InterfaceEncryptTag(tt.input, testKey, TagNameEncrypt, TagValEncrypt); err != nil {
+				t.Fatal(err)
+			}
+			_ = Copy(tt.input)
+		})
+	}
+
+	encrypted, err := StructEncryptTag(struct{ C nilCopier }{nilCopier{"s"}}, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mustDecrypt(t, encrypted.C.Secret) != "s" {
+		t.Errorf("secret = %q, want the reflection copy encrypted when DeepCopy returns nil", encrypted.C.Secret)
+	}
+}
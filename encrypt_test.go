@@ -0,0 +1,483 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testKey is a 16 bytes AES key, hex encoded.
+const testKey = "000102030405060708090a0b0c0d0e0f"
+
+// mustDecrypt decrypts ciphertext with testKey, failing the test on error.
+func mustDecrypt(t *testing.T, ciphertext string) string {
+	t.Helper()
+
+	plaintext, err := Decrypt(ciphertext, testKey)
+	if err != nil {
+		t.Fatalf("Decrypt(%q): %v", ciphertext, err)
+	}
+	return plaintext
+}
+
+// setTestKey makes testKey the key set by SetKeyEncrypt for the duration of the test.
+func setTestKey(t *testing.T) {
+	t.Helper()
+
+	prev, key := keyEncrypt, testKey
+	keyEncrypt = &key
+	t.Cleanup(func() { keyEncrypt = prev })
+}
+
+func TestStringSliceEncrypt(t *testing.T) {
+	input := []string{"token-1", "token-2"}
+
+	encrypted, err := StringSliceEncrypt(input, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encrypted) != len(input) {
+		t.Fatalf("got %d elements, want %d", len(encrypted), len(input))
+	}
+	for i := range input {
+		if encrypted[i] == input[i] || !IsEncrypted(encrypted[i]) {
+			t.Errorf("element %d = %q, want a ciphertext", i, encrypted[i])
+		}
+	}
+	if input[0] != "token-1" {
+		t.Error("StringSliceEncrypt modified its input")
+	}
+
+	decrypted, err := StringSliceDecrypt(encrypted, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range input {
+		if decrypted[i] != input[i] {
+			t.Errorf("decrypted element %d = %q, want %q", i, decrypted[i], input[i])
+		}
+	}
+}
+
+func TestStructEncryptTagStringSliceField(t *testing.T) {
+	type payload struct {
+		Tokens []string `encrypt:"true"`
+		Names  []string
+	}
+	input := payload{Tokens: []string{"secret-a", "secret-b"}, Names: []string{"bob"}}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, token := range encrypted.Tokens {
+		if mustDecrypt(t, token) != input.Tokens[i] {
+			t.Errorf("token %d doesn't decrypt to %q", i, input.Tokens[i])
+		}
+	}
+	if encrypted.Names[0] != "bob" {
+		t.Errorf("untagged slice = %v, want it unchanged", encrypted.Names)
+	}
+	if input.Tokens[0] != "secret-a" {
+		t.Error("StructEncryptTag modified its input")
+	}
+
+	decrypted, err := StructDecryptTag(encrypted, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Tokens[0] != "secret-a" || decrypted.Tokens[1] != "secret-b" {
+		t.Errorf("decrypted tokens = %v", decrypted.Tokens)
+	}
+}
+
+func TestStructEncryptTagSliceFields(t *testing.T) {
+	type item struct {
+		Secret string `encrypt:"true"`
+	}
+	type payload struct {
+		Ptrs    []*string `encrypt:"true"`
+		Items   []item
+		Missing []string `encrypt:"true"`
+	}
+	a, b := "secret-a", "secret-b"
+	input := payload{Ptrs: []*string{&a, nil, &b}, Items: []item{{"i1"}, {"i2"}}}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mustDecrypt(t, *encrypted.Ptrs[0]) != "secret-a" || encrypted.Ptrs[1] != nil || mustDecrypt(t, *encrypted.Ptrs[2]) != "secret-b" {
+		t.Errorf("pointers = %v, want each element encrypted and nil kept", encrypted.Ptrs)
+	}
+	if encrypted.Ptrs[0] == &a || a != "secret-a" {
+		t.Error("StructEncryptTag modified the strings pointed to by its input")
+	}
+	for i, it := range encrypted.Items {
+		if mustDecrypt(t, it.Secret) != input.Items[i].Secret {
+			t.Errorf("item %d = %q, want the untagged slice of structs walked", i, it.Secret)
+		}
+	}
+	if encrypted.Missing != nil {
+		t.Errorf("nil slice = %#v, want it kept nil", encrypted.Missing)
+	}
+
+	decrypted, err := StructDecryptTag(encrypted, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decrypted.Ptrs[0] != "secret-a" || *decrypted.Ptrs[2] != "secret-b" || decrypted.Items[1].Secret != "i2" {
+		t.Errorf("decrypted = %+v, want the plaintexts back", decrypted)
+	}
+}
+
+type embeddedSecret struct {
+	Secret string `encrypt:"true"`
+}
+
+type EmbeddedSecret struct {
+	Secret string `encrypt:"true"`
+}
+
+func TestStructEncryptTagEmbedded(t *testing.T) {
+	// the fields promoted from an unexported embedded struct are encoded by encoding/json too
+	type byValue struct {
+		embeddedSecret
+		Name string
+	}
+	type byPointer struct {
+		*EmbeddedSecret
+		Name string
+	}
+
+	value, err := StructEncryptTag(byValue{embeddedSecret{"s1"}, "a"}, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mustDecrypt(t, value.Secret) != "s1" || value.Name != "a" {
+		t.Errorf("value embed = %+v, want the promoted secret encrypted", value)
+	}
+
+	ptr, err := StructEncryptTag(byPointer{&EmbeddedSecret{"s2"}, "b"}, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mustDecrypt(t, ptr.Secret) != "s2" {
+		t.Errorf("pointer embed secret = %q, want a ciphertext of s2", ptr.Secret)
+	}
+
+	nilPtr, err := StructEncryptTag(byPointer{Name: "c"}, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nilPtr.EmbeddedSecret != nil || nilPtr.Name != "c" {
+		t.Errorf("nil pointer embed = %+v, want it left nil", nilPtr)
+	}
+}
+
+func TestStructEncryptTagUnexportedField(t *testing.T) {
+	type dto struct {
+		Secret string `encrypt:"true"`
+		helper string
+	}
+
+	for _, input := range []interface{}{dto{Secret: "s", helper: "h"}, &dto{Secret: "s", helper: "h"}} {
+		encrypted, err := StructEncryptTagInterface(input, testKey, TagNameEncrypt, TagValEncrypt)
+		if err != nil {
+			t.Fatalf("%T: %v", input, err)
+		}
+		var got dto
+		if p, ok := encrypted.(*dto); ok {
+			got = *p
+		} else {
+			got = encrypted.(dto)
+		}
+		if mustDecrypt(t, got.Secret) != "s" {
+			t.Errorf("%T: secret = %q, want a ciphertext of s", input, got.Secret)
+		}
+	}
+}
+
+// panicCopier panics when the walkers copy it.
+type panicCopier struct{}
+
+func (panicCopier) DeepCopy() interface{} { panic("broken copier") }
+
+func TestStructEncryptTagRecoversPanic(t *testing.T) {
+	type dto struct {
+		Secret string `encrypt:"true"`
+		Copier panicCopier
+	}
+
+	res, err := StructEncryptTag(dto{Secret: "s"}, testKey, TagNameEncrypt, TagValEncrypt)
+	if err == nil || !strings.HasPrefix(err.Error(), "encrypt: ") {
+		t.Fatalf("err = %v, want the panic as an encrypt error", err)
+	}
+	if res.Secret != "" {
+		t.Errorf("secret = %q, want the zero value returned along with the error", res.Secret)
+	}
+
+	_, err = StructDecryptTag(dto{Secret: "s"}, testKey, TagNameEncrypt, TagValEncrypt)
+	if err == nil || !strings.HasPrefix(err.Error(), "decrypt: ") {
+		t.Errorf("err = %v, want the panic as a decrypt error", err)
+	}
+}
+
+func TestSetEncryptionEnabled(t *testing.T) {
+	setTestKey(t)
+	SetEncryptionEnabled(false)
+	t.Cleanup(func() { SetEncryptionEnabled(true) })
+
+	type dto struct {
+		Secret string `encrypt:"true"`
+	}
+	input := dto{Secret: "plain"}
+
+	if got, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt); err != nil || got.Secret != "plain" {
+		t.Errorf("StructEncryptTag = %+v, %v, want the input unchanged", got, err)
+	}
+	if got, err := EncryptLog(input); err != nil || got.Secret != "plain" {
+		t.Errorf("EncryptLog = %+v, %v, want the input unchanged", got, err)
+	}
+	if got, err := EncryptInterface(input); err != nil || got.(dto).Secret != "plain" {
+		t.Errorf("EncryptInterface = %+v, %v, want the input unchanged", got, err)
+	}
+	if !HasKeyEncrypt() {
+		t.Error("disabling encryption unset the key")
+	}
+
+	SetEncryptionEnabled(true)
+	if got, err := EncryptLog(input); err != nil || mustDecrypt(t, got.Secret) != "plain" {
+		t.Errorf("EncryptLog = %+v, %v, want the secret encrypted once enabled again", got, err)
+	}
+}
+
+type credentials struct {
+	APIToken string
+	Note     string
+}
+
+type account struct {
+	UserPassword string
+	CardNumber   *string
+	SSN          []string
+	Name         string
+	TokenCount   int
+	Tagged       string `encrypt:"true"`
+	Credentials  credentials
+	Keys         []credentials
+}
+
+func TestStructEncryptByName(t *testing.T) {
+	setTestKey(t)
+	SetAutoEncryptFieldPatterns([]string{"*password*", "*Token*", "*SECRET*", "CardNumber", "SSN"})
+	t.Cleanup(func() { SetAutoEncryptFieldPatterns(nil) })
+
+	card := "4111"
+	input := &account{
+		UserPassword: "hunter2",
+		CardNumber:   &card,
+		SSN:          []string{"078-05-1120"},
+		Name:         "bob",
+		TokenCount:   3,
+		Tagged:       "tagged",
+		Credentials:  credentials{APIToken: "tok", Note: "note"},
+		Keys:         []credentials{{APIToken: "tok", Note: "note"}},
+	}
+
+	res, err := StructEncryptByName(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := res.(*account)
+
+	for name, tt := range map[string]struct{ got, want string }{
+		"UserPassword":         {got.UserPassword, "hunter2"},
+		"CardNumber":           {*got.CardNumber, "4111"},
+		"SSN":                  {got.SSN[0], "078-05-1120"},
+		"Tagged":               {got.Tagged, "tagged"},
+		"Credentials.APIToken": {got.Credentials.APIToken, "tok"},
+		"Keys.APIToken":        {got.Keys[0].APIToken, "tok"},
+	} {
+		if mustDecrypt(t, tt.got) != tt.want {
+			t.Errorf("%s = %s, want the ciphertext of %s", name, tt.got, tt.want)
+		}
+	}
+	if got.Name != "bob" || got.TokenCount != 3 || got.Credentials.Note != "note" || got.Keys[0].Note != "note" {
+		t.Errorf("got %+v, want the fields matching no pattern unchanged", got)
+	}
+	if input.UserPassword != "hunter2" || card != "4111" {
+		t.Errorf("input %+v, want it unchanged", input)
+	}
+
+	if res, err := StructEncryptByName(5); res != 5 || err != nil {
+		t.Errorf("StructEncryptByName(5) = %v, %v, want the input unchanged", res, err)
+	}
+}
+
+func TestNilInput(t *testing.T) {
+	setTestKey(t)
+	ctx := context.Background()
+
+	type entryPoint struct {
+		name string
+		call func(input interface{}) (interface{}, error)
+	}
+	entryPoints := []entryPoint{
+		{"StructEncryptTag", func(in interface{}) (interface{}, error) { return StructEncryptTag(in, testKey, "encrypt", "true") }},
+		{"StructEncryptTagContext", func(in interface{}) (interface{}, error) {
+			return StructEncryptTagContext(ctx, in, testKey, "encrypt", "true")
+		}},
+		{"StructEncryptTagExcept", func(in interface{}) (interface{}, error) {
+			return StructEncryptTagExcept(in, testKey, "encrypt", "true", "Card")
+		}},
+		{"StructEncryptTags", func(in interface{}) (interface{}, error) { return StructEncryptTags(in, testKey, "encrypt", "true") }},
+		{"StructSliceEncryptTag", func(in interface{}) (interface{}, error) {
+			return StructSliceEncryptTag(in, testKey, "encrypt", "true")
+		}},
+		{"InterfaceEncryptTag", func(in interface{}) (interface{}, error) {
+			return InterfaceEncryptTag(in, testKey, "encrypt", "true")
+		}},
+		{"StructDecryptTag", func(in interface{}) (interface{}, error) { return StructDecryptTag(in, testKey, "encrypt", "true") }},
+		{"StructSliceDecryptTag", func(in interface{}) (interface{}, error) {
+			return StructSliceDecryptTag(in, testKey, "encrypt", "true")
+		}},
+		{"InterfaceDecryptTag", func(in interface{}) (interface{}, error) {
+			return InterfaceDecryptTag(in, testKey, "encrypt", "true")
+		}},
+		{"StructEncryptTagInterface", func(in interface{}) (interface{}, error) {
+			return StructEncryptTagInterface(in, testKey, "encrypt", "true")
+		}},
+		{"StructSliceEncryptTagInterface", func(in interface{}) (interface{}, error) {
+			return StructSliceEncryptTagInterface(in, testKey, "encrypt", "true")
+		}},
+		{"InterfaceEncryptTagInterface", func(in interface{}) (interface{}, error) {
+			return InterfaceEncryptTagInterface(in, testKey, "encrypt", "true")
+		}},
+		{"InterfaceEncryptTagContext", func(in interface{}) (interface{}, error) {
+			return InterfaceEncryptTagContext(ctx, in, testKey, "encrypt", "true")
+		}},
+		{"EncryptLog", func(in interface{}) (interface{}, error) { return EncryptLog(in) }},
+		{"EncryptInterface", EncryptInterface},
+		{"EncryptIfMarked", EncryptIfMarked},
+		{"StructEncryptByName", StructEncryptByName},
+	}
+
+	type card struct {
+		Card string `encrypt:"true"`
+	}
+	var empty interface{}
+	inputs := []interface{}{nil, (*card)(nil), empty, (*string)(nil), []card(nil)}
+	for _, ep := range entryPoints {
+		for _, in := range inputs {
+			got, err := ep.call(in)
+			if err != nil {
+				t.Errorf("%s(%#v) = %v", ep.name, in, err)
+			}
+			if !reflect.DeepEqual(got, in) {
+				t.Errorf("%s(%#v) = %#v, want the input", ep.name, in, got)
+			}
+		}
+	}
+
+	if got, err := StructEncryptTag((*card)(nil), testKey, "encrypt", "true"); got != nil || err != nil {
+		t.Errorf("StructEncryptTag of a typed nil pointer = %v, %v, want nil, nil", got, err)
+	}
+	if got, err := StructDecryptTag((*card)(nil), testKey, "encrypt", "true"); got != nil || err != nil {
+		t.Errorf("StructDecryptTag of a typed nil pointer = %v, %v, want nil, nil", got, err)
+	}
+	if got, err := EncryptLog((*string)(nil)); got != nil || err != nil {
+		t.Errorf("EncryptLog of a nil string pointer = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestStructEncryptTags(t *testing.T) {
+	type address struct {
+		Street string `log:"pii"`
+		City   string
+	}
+	type profile struct {
+		Name     string `log:"pii"`
+		Token    string `log:"secret"`
+		Email    string `log:"public"`
+		Note     string
+		Address  address
+		Previous []address
+	}
+	input := &profile{
+		Name:     "bob",
+		Token:    "tok",
+		Email:    "bob@example.com",
+		Note:     "note",
+		Address:  address{Street: "1 Main St", City: "Paris"},
+		Previous: []address{{Street: "2 Side St", City: "Lyon"}},
+	}
+
+	got, err := StructEncryptTags(input, testKey, "log", "pii", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []struct{ got, want string }{
+		{got.Name, "bob"},
+		{got.Token, "tok"},
+		{got.Address.Street, "1 Main St"},
+		{got.Previous[0].Street, "2 Side St"},
+	} {
+		if mustDecrypt(t, field.got) != field.want {
+			t.Errorf("field = %s, want the ciphertext of %s", field.got, field.want)
+		}
+	}
+	if got.Email != input.Email || got.Note != input.Note || got.Address.City != "Paris" || got.Previous[0].City != "Lyon" {
+		t.Errorf("got %+v, want the fields with other tag values unchanged", got)
+	}
+	if input.Name != "bob" {
+		t.Errorf("input %+v, want it unchanged", input)
+	}
+
+	if same, err := StructEncryptTags(input, testKey, "log"); same != input || err != nil {
+		t.Errorf("StructEncryptTags without tag values = %v, %v, want the input", same, err)
+	}
+}
+
+func TestStructSliceEncryptTagFunc(t *testing.T) {
+	type payment struct {
+		Card   string `encrypt:"true"`
+		Masked bool
+	}
+	input := []payment{{Card: "4111"}, {Card: "4xxx", Masked: true}, {Card: "5500"}, {Card: "5xxx", Masked: true}}
+
+	got, err := StructSliceEncryptTagFunc(input, testKey, TagNameEncrypt, TagValEncrypt, func(index int, item interface{}) bool {
+		return index%2 == 0 && !item.(payment).Masked
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range got {
+		if i%2 == 1 {
+			if p != input[i] {
+				t.Errorf("item %d = %+v, want it skipped", i, p)
+			}
+		} else if mustDecrypt(t, p.Card) != input[i].Card {
+			t.Errorf("item %d = %+v, want its card encrypted", i, p)
+		}
+	}
+	if input[0].Card != "4111" {
+		t.Errorf("input %+v, want it unchanged", input)
+	}
+
+	all, err := StructSliceEncryptTagFunc(input, testKey, TagNameEncrypt, TagValEncrypt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range all {
+		if mustDecrypt(t, p.Card) != input[i].Card {
+			t.Errorf("item %d = %+v, want every item encrypted with a nil include", i, p)
+		}
+	}
+
+	if _, err := StructSliceEncryptTagFunc(payment{}, testKey, TagNameEncrypt, TagValEncrypt, nil); !errors.Is(err, ErrNotSlice) {
+		t.Errorf("err = %v, want ErrNotSlice", err)
+	}
+}
@@ -0,0 +1,19 @@
+package logger
+
+import "errors"
+
+var (
+	// ErrNotStruct is returned when walking an input that isn't a struct or a pointer to struct.
+	ErrNotStruct = errors.New("input is not a struct")
+	// ErrNotSlice is returned when walking an input that isn't a slice.
+	ErrNotSlice = errors.New("input is not a slice")
+	// ErrUnsettable is returned for a tagged field the walker can't set to its ciphertext or plaintext.
+	ErrUnsettable = errors.New("field can't be set")
+	// ErrDecrypt is returned by Decrypt for any ciphertext it can't decrypt, whether it fails to decode
+	// or fails its MAC or padding check, so that the error doesn't tell apart the failures.
+	ErrDecrypt = errors.New("decrypt: invalid ciphertext")
+	// ErrNoKey is returned when the encryption key needed isn't set or registered.
+	ErrNoKey = errors.New("encryption key is not set")
+	// ErrSyslogUnsupported is returned by NewSyslogWriter on the platforms without syslog, like Windows.
+	ErrSyslogUnsupported = errors.New("syslog is not supported on this platform")
+)
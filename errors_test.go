@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorSentinels(t *testing.T) {
+	prev, key := keyEncrypt, ""
+	keyEncrypt = &key
+	t.Cleanup(func() { keyEncrypt = prev })
+
+	type number struct {
+		N int `encrypt:"true"`
+	}
+	type named struct {
+		S string `encrypt:"enc:missing"`
+	}
+	_, structNotStruct := StructEncryptTag(42, testKey, TagNameEncrypt, TagValEncrypt)
+	_, decryptNotStruct := StructDecryptTag("text", testKey, TagNameEncrypt, TagValEncrypt)
+	_, sliceNotSlice := StructSliceEncryptTag(number{}, testKey, TagNameEncrypt, TagValEncrypt)
+	_, decryptNotSlice := StructSliceDecryptTag(number{}, testKey, TagNameEncrypt, TagValEncrypt)
+	_, funcNotSlice := StructSliceEncryptTagFunc(number{}, testKey, TagNameEncrypt, TagValEncrypt, nil)
+	_, unsettable := StructEncryptTag(number{N: 1}, testKey, TagNameEncrypt, TagValEncrypt)
+	_, decrypt := Decrypt("ENC:!invalid", testKey)
+	_, unregistered := StructEncryptTag(named{S: "s"}, testKey, TagNameEncrypt, TagValEncrypt)
+	c := newEchoContext()
+	c.SetRequest(c.Request().WithContext(WithRequestBody(c.Request().Context(), `{"a":"b"}`)))
+	_, noKey := GetEchoReqDecrLog(c)
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+	}{
+		{"StructEncryptTag", structNotStruct, ErrNotStruct},
+		{"StructDecryptTag", decryptNotStruct, ErrNotStruct},
+		{"StructSliceEncryptTag", sliceNotSlice, ErrNotSlice},
+		{"StructSliceDecryptTag", decryptNotSlice, ErrNotSlice},
+		{"StructSliceEncryptTagFunc", funcNotSlice, ErrNotSlice},
+		{"StructEncryptTag of a tagged number", unsettable, ErrUnsettable},
+		{"Decrypt", decrypt, ErrDecrypt},
+		{"StructEncryptTag with an unregistered key", unregistered, ErrNoKey},
+		{"GetEchoReqDecrLog without a key", noKey, ErrNoKey},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.target) {
+			t.Errorf("%s: err = %v, want %v", tt.name, tt.err, tt.target)
+		}
+	}
+}
@@ -2,8 +2,15 @@ package logger
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -11,6 +18,8 @@ import (
 
 type Event struct {
 	event *zerolog.Event
+	skip  int         // frames set by CallerSkipFrame, skipped by Caller
+	names *FieldNames // field names of the logger of the event, nil to use the global ones
 }
 
 func (e *Event) Enabled() bool {
@@ -38,8 +47,10 @@ func (e *Event) MsgFunc(createMsg func() string) {
 	e.event.MsgFunc(createMsg)
 }
 
+// Fields adds fields, a map[string]interface{} or a []interface{} of key-value pairs.
+// The values of a map are encrypted as set by EncryptEventFields.
 func (e *Event) Fields(fields interface{}) *Event {
-	e.event.Fields(fields)
+	e.event.Fields(encryptFieldValues(fields))
 	return e
 }
 
@@ -48,6 +59,12 @@ func (e *Event) Dict(key string, dict *Event) *Event {
 	return e
 }
 
+// DictFields adds fields nested under key, their values being encrypted as set by EncryptEventFields.
+func (e *Event) DictFields(key string, fields map[string]interface{}) *Event {
+	e.event.Dict(key, zerolog.Dict().Fields(encryptFieldValues(fields)))
+	return e
+}
+
 func (e *Event) Array(key string, arr zerolog.LogArrayMarshaler) *Event {
 	e.event.Array(key, arr)
 	return e
@@ -58,9 +75,11 @@ func (e *Event) Object(key string, obj zerolog.LogObjectMarshaler) *Event {
 	return e
 }
 
+// Func calls f with the event only when it is enabled, so that the fields costly to build,
+// e.g. a dump of a big object, are skipped for the disabled levels.
 func (e *Event) Func(f func(e *Event)) *Event {
 	e.event.Func(func(event *zerolog.Event) {
-		f(&Event{event})
+		f(&Event{event: event, skip: e.skip, names: e.names})
 	})
 	return e
 }
@@ -90,8 +109,16 @@ func (e *Event) Stringers(key string, vals []fmt.Stringer) *Event {
 	return e
 }
 
+// Bytes adds val in the encoding set by SetBinaryEncoding, as a string by default.
 func (e *Event) Bytes(key string, val []byte) *Event {
-	e.event.Bytes(key, val)
+	switch currentBinaryEncoding() {
+	case BinaryBase64:
+		e.event.Str(key, base64.StdEncoding.EncodeToString(val))
+	case BinaryHex:
+		e.event.Hex(key, val)
+	default:
+		e.event.Bytes(key, val)
+	}
 	return e
 }
 
@@ -125,11 +152,26 @@ func (e *Event) Err(err error) *Event {
 	return e
 }
 
+// Stack adds the stack of the caller under the FileError field of the event's logger to this event only,
+// as StackTrace does for every event of a logger. Called before Err, it also adds the stack carried
+// by the error under zerolog.ErrorStackFieldName.
 func (e *Event) Stack() *Event {
-	e.event.Stack()
+	if e.event == nil {
+		return e
+	}
+
+	e.event.Stack().Str(e.fieldNames().FileError, GetFullStack())
 	return e
 }
 
+// fieldNames returns the field names of the logger of the event.
+func (e *Event) fieldNames() FieldNames {
+	if e.names != nil {
+		return *e.names
+	}
+	return GetFieldNames()
+}
+
 func (e *Event) Ctx(ctx context.Context) *Event {
 	e.event.Ctx(ctx)
 	return e
@@ -299,6 +341,224 @@ func (e *Event) TimeDiff(key string, t time.Time, start time.Time) *Event {
 	return e
 }
 
+// Latency adds the time elapsed since start under KeyLatency, in milliseconds.
+func (e *Event) Latency(start time.Time) *Event {
+	return e.DurationMs(KeyLatency, time.Since(start))
+}
+
+// DurationMs adds d as a float number of milliseconds.
+func (e *Event) DurationMs(key string, d time.Duration) *Event {
+	e.event.Float64(key, float64(d)/float64(time.Millisecond))
+	return e
+}
+
+// BinaryEncoding is the encoding of the byte slices added by Bytes.
+type BinaryEncoding int32
+
+const (
+	// BinaryRaw writes the bytes as a string, as zerolog does. It is the default.
+	BinaryRaw BinaryEncoding = iota
+	// BinaryBase64 encodes the bytes in standard base64.
+	BinaryBase64
+	// BinaryHex encodes the bytes in hexadecimal, as Hex.
+	BinaryHex
+)
+
+var binaryEncoding atomic.Int32
+
+// SetBinaryEncoding sets the encoding of the byte slices added by Event.Bytes and Context.Bytes.
+func SetBinaryEncoding(enc BinaryEncoding) {
+	binaryEncoding.Store(int32(enc))
+}
+
+func currentBinaryEncoding() BinaryEncoding {
+	return BinaryEncoding(binaryEncoding.Load())
+}
+
+var encryptEventFields atomic.Bool
+
+// EncryptEventFields sets whether Event.Fields and Event.DictFields encrypt the tagged fields of the
+// struct and slice values of a map, with the key set by SetKeyEncrypt and the default encrypt tag.
+func EncryptEventFields(enabled bool) {
+	encryptEventFields.Store(enabled)
+}
+
+// encryptFieldValues returns a copy of the map fields with its values encrypted by the tag walker,
+// or fields as is when EncryptEventFields is disabled or no key is set.
+func encryptFieldValues(fields interface{}) interface{} {
+	m, ok := fields.(map[string]interface{})
+	if !ok || !encryptEventFields.Load() || keyEncrypt == nil || *keyEncrypt == "" {
+		return fields
+	}
+
+	tagName, tagVal := defaultEncryptTag()
+	encrypted := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		value, err := InterfaceEncryptTagInterface(v, *keyEncrypt, tagName, tagVal)
+		if err != nil {
+			value = encryptErrValue
+		}
+		encrypted[k] = value
+	}
+	return encrypted
+}
+
+// encryptErrValue replaces the values that failed to be encrypted, so they are never logged in plaintext.
+const encryptErrValue = "[ENCRYPT-ERR]"
+
+// EncStr adds value encrypted with the key set by SetKeyEncrypt, or in plaintext when no key is set
+// or encryption is disabled.
+func (e *Event) EncStr(key, value string) *Event {
+	e.event.Str(key, encryptValue(value))
+	return e
+}
+
+// encryptValue encrypts value with the key set by SetKeyEncrypt, or returns it as is when no key is set
+// or encryption is disabled.
+func encryptValue(value string) string {
+	if keyEncrypt == nil || *keyEncrypt == "" || encryptionDisabled.Load() {
+		return value
+	}
+
+	encrypted, err := Encrypt(value, *keyEncrypt)
+	if err != nil {
+		return encryptErrValue
+	}
+	return encrypted
+}
+
+// ErrSecure adds err as Err does, with the message of each error it wraps encrypted as by EncStr,
+// e.g. "ENC:...: ENC:..." for an error wrapping another, so that the user data the messages may hold
+// is never logged in plaintext. The type names of the errors are added in plaintext under KeyErrType.
+func (e *Event) ErrSecure(err error) *Event {
+	if e.event == nil || err == nil {
+		return e
+	}
+
+	var messages, types []string
+	for err != nil {
+		msg := err.Error()
+		types = append(types, fmt.Sprintf("%T", err))
+		inner := errors.Unwrap(err)
+		// only the message the error adds to the one it wraps, when it ends with it
+		if inner != nil && strings.HasSuffix(msg, inner.Error()) {
+			msg = strings.TrimSuffix(strings.TrimSuffix(msg, inner.Error()), ": ")
+		} else {
+			inner = nil
+		}
+		messages = append(messages, encryptValue(msg))
+		err = inner
+	}
+	e.event.Str(zerolog.ErrorFieldName, strings.Join(messages, ": ")).
+		Str(KeyErrType, strings.Join(types, ": "))
+	return e
+}
+
+// EncInterface adds v with its tagged fields encrypted as by EncryptInterface.
+func (e *Event) EncInterface(key string, v interface{}) *Event {
+	encrypted, err := EncryptInterface(v)
+	if err != nil {
+		encrypted = encryptErrValue
+	}
+	e.event.Interface(key, encrypted)
+	return e
+}
+
+// redactedValue replaces the validation messages of the fields tagged for encryption.
+const redactedValue = "[REDACTED]"
+
+// FieldError is the validation error of a field, as logged by FieldErrors.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationErrors adds errs, mapping field names to validation messages, as an object under
+// KeyValidationErrors, sorted by field name. See FieldErrors for the redacted messages.
+func (e *Event) ValidationErrors(errs map[string]string, input ...interface{}) *Event {
+	fieldErrs := make([]FieldError, 0, len(errs))
+	for field, msg := range errs {
+		fieldErrs = append(fieldErrs, FieldError{Field: field, Message: msg})
+	}
+	sort.Slice(fieldErrs, func(i, j int) bool { return fieldErrs[i].Field < fieldErrs[j].Field })
+	return e.FieldErrors(fieldErrs, input...)
+}
+
+// FieldErrors adds errs as an object under KeyValidationErrors mapping each field to its message.
+// The message of a sensitive field is replaced with "[REDACTED]", since it may quote the value in any form:
+// a field named as set by SetAutoEncryptFieldPatterns, or given the validated struct as input, a field
+// tagged for encryption. A field is named by its Go or JSON name, and the fields of nested structs
+// by dot-separated paths, e.g. "Billing.card".
+func (e *Event) FieldErrors(errs []FieldError, input ...interface{}) *Event {
+	if !e.Enabled() {
+		return e
+	}
+
+	dict := zerolog.Dict()
+	for _, fe := range errs {
+		msg := fe.Message
+		if sensitiveField(fe.Field, input...) {
+			msg = redactedValue
+		}
+		dict.Str(fe.Field, msg)
+	}
+	e.event.Dict(KeyValidationErrors, dict)
+	return e
+}
+
+// sensitiveField reports whether the field at the dot-separated path matches a pattern set by
+// SetAutoEncryptFieldPatterns or, given the struct input, is tagged for encryption with the default
+// encrypt tag. The field is looked up in the type of input, so a nil pointer still tells its tags.
+func sensitiveField(path string, input ...interface{}) bool {
+	if matchAutoEncryptField(path[strings.LastIndex(path, ".")+1:]) {
+		return true
+	}
+	if len(input) == 0 || input[0] == nil {
+		return false
+	}
+
+	tagName, tagVal := defaultEncryptTag()
+	t := reflect.TypeOf(input[0])
+	for {
+		name, rest, nested := strings.Cut(path, ".")
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+
+		f, ok := fieldByName(t, name)
+		if !ok {
+			return false
+		}
+		if nested {
+			t, path = f.Type, rest
+			continue
+		}
+
+		if matchAutoEncryptField(f.Name) {
+			return true
+		}
+		tag := f.Tag.Get(tagName)
+		return tag == tagVal || tag == TagValFPE || tag == TagValDeterministic || tag == TagValTime ||
+			strings.HasPrefix(tag, TagValKeyPrefix) || strings.HasPrefix(tag, TagValTimePrefix)
+	}
+}
+
+// fieldByName returns the exported field of the struct type t with the Go or JSON name name.
+func fieldByName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for _, f := range reflect.VisibleFields(t) {
+		if f.PkgPath != "" {
+			continue
+		}
+		if jsonKey, ok := jsonName(f); f.Name == name || ok && jsonKey == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
 func (e *Event) Any(key string, i interface{}) *Event {
 	e.event.Any(key, i)
 	return e
@@ -316,11 +576,40 @@ func (e *Event) Type(key string, val interface{}) *Event {
 
 func (e *Event) CallerSkipFrame(skip int) *Event {
 	e.event.CallerSkipFrame(skip)
+	e.skip += skip
 	return e
 }
 
+// Caller adds the file:line of the code calling it under zerolog.CallerFieldName, formatted by
+// zerolog.CallerMarshalFunc with the file trimmed as set by SetCallerTrim. skip skips as many more
+// frames, e.g. 1 to report the caller of a logging helper.
 func (e *Event) Caller(skip ...int) *Event {
-	e.event.Caller(skip...)
+	n := 0
+	if len(skip) > 0 {
+		n = skip[0]
+	}
+	return e.caller(n)
+}
+
+// CallerSkip is Caller skipping n more frames.
+func (e *Event) CallerSkip(n int) *Event {
+	return e.caller(n)
+}
+
+// caller adds the caller of the Event method calling it, skipping n more frames, along with those
+// set by CallerSkipFrame and zerolog.CallerSkipFrameCount as zerolog's Caller does.
+func (e *Event) caller(n int) *Event {
+	if !e.Enabled() {
+		return e
+	}
+
+	// zerolog.CallerSkipFrameCount defaults to 2, the frames of caller and of the exported method
+	pc, file, line, ok := runtime.Caller(n + zerolog.CallerSkipFrameCount + e.skip)
+	if !ok {
+		return e
+	}
+	file = trimCallerFile(file, runtime.FuncForPC(pc).Name())
+	e.event.Str(zerolog.CallerFieldName, zerolog.CallerMarshalFunc(pc, file, line))
 	return e
 }
 
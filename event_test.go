@@ -0,0 +1,346 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestDurationMs(t *testing.T) {
+	l, buf := newTestLogger()
+
+	l.Info().DurationMs("elapsed", 1500*time.Microsecond).Msg("timed")
+	l.Info().Latency(time.Now().Add(-time.Second)).Msg("request")
+
+	entries := decodeLines(t, buf)
+	if entries[0]["elapsed"] != 1.5 {
+		t.Errorf("elapsed = %v, want 1.5 milliseconds", entries[0]["elapsed"])
+	}
+	if latency, _ := entries[1][KeyLatency].(float64); latency < 1000 || latency > 2000 {
+		t.Errorf("latency = %v, want about 1000 milliseconds", entries[1][KeyLatency])
+	}
+}
+
+func TestEncStr(t *testing.T) {
+	setTestKey(t)
+	l, buf := newTestLogger()
+
+	l.Info().EncStr("pan", "4111111111111111").Msg("paid")
+
+	out := buf.String()
+	if strings.Contains(out, "4111111111111111") {
+		t.Fatalf("output = %s, want no plaintext", out)
+	}
+	if pan := decodeLines(t, buf)[0]["pan"].(string); mustDecrypt(t, pan) != "4111111111111111" {
+		t.Errorf("pan = %q, want a ciphertext of the value", pan)
+	}
+}
+
+func TestEncStrInvalidKey(t *testing.T) {
+	prev, key := keyEncrypt, "not hex"
+	keyEncrypt = &key
+	t.Cleanup(func() { keyEncrypt = prev })
+	l, buf := newTestLogger()
+
+	l.Info().EncStr("pan", "4111111111111111").EncInterface("card", struct {
+		PAN string `encrypt:"true"`
+	}{"4111"}).Msg("paid")
+
+	entry := decodeLines(t, buf)[0]
+	if entry["pan"] != encryptErrValue || entry["card"] != encryptErrValue {
+		t.Errorf("entry = %v, want %s in place of the values", entry, encryptErrValue)
+	}
+}
+
+func TestEncInterface(t *testing.T) {
+	setTestKey(t)
+	l, buf := newTestLogger()
+
+	type card struct {
+		PAN  string `encrypt:"true"`
+		Name string
+	}
+	l.Info().EncInterface("card", card{PAN: "4111111111111111", Name: "bob"}).Msg("paid")
+
+	out := buf.String()
+	if strings.Contains(out, "4111111111111111") {
+		t.Fatalf("output = %s, want no plaintext", out)
+	}
+	got := decodeLines(t, buf)[0]["card"].(map[string]interface{})
+	if got["Name"] != "bob" || mustDecrypt(t, got["PAN"].(string)) != "4111111111111111" {
+		t.Errorf("card = %v, want only its tagged field encrypted", got)
+	}
+}
+
+func TestWithAutoStackOnError(t *testing.T) {
+	l, buf := newTestLogger(WithAutoStackOnError(true))
+
+	l.Info().Msg("info")
+	l.Error().Msg("error")
+	l.Err(errors.New("boom")).Msg("err")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if _, ok := entries[0][KeyFileError]; ok {
+		t.Errorf("info entry = %v, want no stack", entries[0])
+	}
+	for _, entry := range entries[1:] {
+		if stack, _ := entry[KeyFileError].(string); !strings.Contains(stack, "TestWithAutoStackOnError") {
+			t.Errorf("entry = %v, want the stack of the caller", entry)
+		}
+	}
+
+	l, buf = newTestLogger()
+	l.Error().Msg("error")
+	if _, ok := decodeLines(t, buf)[0][KeyFileError]; ok {
+		t.Error("error entry carries a stack without WithAutoStackOnError")
+	}
+}
+
+func TestEventStack(t *testing.T) {
+	l, buf := newTestLogger()
+	renamed, renamedBuf := newTestLogger(WithFieldNames(FieldNames{FileError: "error.stack"}))
+
+	l.Error().Stack().Err(errors.New("boom")).Msg("with stack")
+	l.Error().Msg("unrelated")
+	renamed.Error().Stack().Msg("renamed")
+
+	entries := decodeLines(t, buf)
+	if stack, _ := entries[0][KeyFileError].(string); !strings.Contains(stack, "TestEventStack") {
+		t.Errorf("entry = %v, want the stack of the caller", entries[0])
+	}
+	if _, ok := entries[1][KeyFileError]; ok {
+		t.Errorf("unrelated entry = %v, want no stack", entries[1])
+	}
+	if stack, _ := decodeLines(t, renamedBuf)[0]["error.stack"].(string); !strings.Contains(stack, "TestEventStack") {
+		t.Error("renamed entry carries no stack under the field name of its logger")
+	}
+}
+
+func TestEventFunc(t *testing.T) {
+	l, buf := newTestLogger(WithLogLevel(zerolog.InfoLevel))
+
+	calls := 0
+	expensive := func(e *Event) {
+		calls++
+		e.Str("dump", "big")
+	}
+	l.Debug().Func(expensive).Msg("disabled")
+	l.Info().Func(expensive).Msg("enabled")
+
+	if calls != 1 {
+		t.Errorf("func called %d times, want only for the enabled event", calls)
+	}
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 || entries[0]["dump"] != "big" {
+		t.Errorf("entries %v, want the enabled event with the field added by the func", entries)
+	}
+
+	if l.Enabled(zerolog.DebugLevel) || !l.Enabled(zerolog.InfoLevel) || l.Enabled(zerolog.Disabled) {
+		t.Error("Enabled doesn't match the level of the logger")
+	}
+	prev := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prev) })
+	if l.Enabled(zerolog.WarnLevel) {
+		t.Error("Enabled reports a level below the global level")
+	}
+}
+
+type emailTakenError struct {
+	email string
+}
+
+func (e *emailTakenError) Error() string {
+	return "email already exists: " + e.email
+}
+
+func TestErrSecure(t *testing.T) {
+	setTestKey(t)
+	l, buf := newTestLogger()
+
+	err := fmt.Errorf("signup: %w", &emailTakenError{email: "bob@example.com"})
+	l.Error().ErrSecure(err).Msg("signup failed")
+	l.Error().ErrSecure(nil).Msg("no error")
+
+	entries := decodeLines(t, buf)
+	if strings.Contains(buf.String(), "bob@example.com") {
+		t.Fatalf("output %s, want the raw message left out", buf)
+	}
+	layers := strings.Split(entries[0]["error"].(string), ": ")
+	if len(layers) != 2 || mustDecrypt(t, layers[0]) != "signup" || mustDecrypt(t, layers[1]) != "email already exists: bob@example.com" {
+		t.Errorf("error = %v, want each layer encrypted", entries[0]["error"])
+	}
+	if got := entries[0][KeyErrType]; got != "*fmt.wrapError: *logger.emailTakenError" {
+		t.Errorf("%s = %v, want the type of each layer", KeyErrType, got)
+	}
+	if _, ok := entries[1]["error"]; ok {
+		t.Errorf("entry %v, want no error for a nil error", entries[1])
+	}
+}
+
+func TestEventFields(t *testing.T) {
+	l, buf := newTestLogger()
+
+	l.Info().Fields(map[string]interface{}{"a": 1, "b": "x"}).Msg("flat")
+	l.Info().DictFields("ctx", map[string]interface{}{"a": 1, "b": "x"}).Msg("nested")
+
+	entries := decodeLines(t, buf)
+	if entries[0]["a"] != float64(1) || entries[0]["b"] != "x" {
+		t.Errorf("flat entry = %v, want the fields at the top level", entries[0])
+	}
+	nested, _ := entries[1]["ctx"].(map[string]interface{})
+	if nested["a"] != float64(1) || nested["b"] != "x" {
+		t.Errorf("nested entry = %v, want the fields under ctx", entries[1])
+	}
+}
+
+func TestEventFieldsEncrypted(t *testing.T) {
+	setTestKey(t)
+	EncryptEventFields(true)
+	t.Cleanup(func() { EncryptEventFields(false) })
+	l, buf := newTestLogger()
+
+	type card struct {
+		PAN string `encrypt:"true"`
+	}
+	fields := map[string]interface{}{"card": card{"4111"}, "n": 1}
+	l.Info().Fields(fields).DictFields("nested", fields).Msg("encrypted")
+
+	if strings.Contains(buf.String(), "4111") {
+		t.Fatalf("output = %s, want no plaintext", buf.String())
+	}
+	entry := decodeLines(t, buf)[0]
+	flat := entry["card"].(map[string]interface{})
+	nested := entry["nested"].(map[string]interface{})["card"].(map[string]interface{})
+	if mustDecrypt(t, flat["PAN"].(string)) != "4111" || mustDecrypt(t, nested["PAN"].(string)) != "4111" {
+		t.Errorf("entry = %v, want the tagged fields of the values encrypted", entry)
+	}
+	if fields["card"].(card).PAN != "4111" {
+		t.Error("Fields modified the map passed")
+	}
+}
+
+func TestBytesEncoding(t *testing.T) {
+	t.Cleanup(func() { SetBinaryEncoding(BinaryRaw) })
+	val := []byte("hi\x00")
+	tests := []struct {
+		enc  BinaryEncoding
+		want string
+	}{
+		{BinaryRaw, "hi\x00"},
+		{BinaryBase64, "aGkA"},
+		{BinaryHex, "686900"},
+	}
+
+	for _, tt := range tests {
+		SetBinaryEncoding(tt.enc)
+		l, buf := newTestLogger()
+		scoped := l.With().Bytes("ctx", val).Logger()
+		scoped.Info().Bytes("event", val).Hex("hex", val).Msg("binary")
+
+		entry := decodeLines(t, buf)[0]
+		if entry["event"] != tt.want || entry["ctx"] != tt.want {
+			t.Errorf("encoding %d: event = %q, context = %q, want %q", tt.enc, entry["event"], entry["ctx"], tt.want)
+		}
+		if entry["hex"] != "686900" {
+			t.Errorf("encoding %d: hex = %q, want 686900 whatever the encoding", tt.enc, entry["hex"])
+		}
+	}
+}
+
+// logCaller logs an event reporting the caller of logCaller.
+func logCaller(l *Logger) {
+	l.Info().CallerSkip(1).Msg("helper")
+}
+
+// logCallerSkipFrame logs an event reporting the caller of logCallerSkipFrame through CallerSkipFrame.
+func logCallerSkipFrame(l *Logger) {
+	l.Info().CallerSkipFrame(1).Caller().Msg("helper")
+}
+
+func TestEventCaller(t *testing.T) {
+	l, buf := newTestLogger()
+
+	_, _, line, _ := runtime.Caller(0)
+	l.Info().Caller().Msg("direct")
+	logCaller(l)
+	logCallerSkipFrame(l)
+	l.Info().Msg("no caller")
+
+	entries := decodeLines(t, buf)
+	for i, e := range entries[:3] {
+		want := "event_test.go:" + strconv.Itoa(line+1+i)
+		if got, _ := e[zerolog.CallerFieldName].(string); !strings.HasSuffix(got, want) {
+			t.Errorf("%s: caller = %q, want it to end with %s", e["message"], got, want)
+		}
+	}
+	if _, ok := entries[3][zerolog.CallerFieldName]; ok {
+		t.Errorf("caller = %v on an event without Caller", entries[3][zerolog.CallerFieldName])
+	}
+}
+
+type billingForm struct {
+	CVV string `json:"cvv" encrypt:"true"`
+	Zip string `json:"zip"`
+}
+
+type signupForm struct {
+	Email    string       `json:"email"`
+	Card     string       `json:"card" encrypt:"true"`
+	Password string       `json:"password"`
+	Billing  *billingForm `json:"billing"`
+}
+
+func TestValidationErrors(t *testing.T) {
+	SetAutoEncryptFieldPatterns([]string{"*password*"})
+	t.Cleanup(func() { SetAutoEncryptFieldPatterns(nil) })
+	l, buf := newTestLogger()
+
+	errs := map[string]string{
+		"email":       "must be an email",
+		"card":        "4111 is not a card number",
+		"password":    "hunter2 is too short",
+		"billing.cvv": "12 must have 3 digits",
+		"billing.zip": "must have 5 digits",
+	}
+	l.Warn().ValidationErrors(errs, (*signupForm)(nil)).Msg("with input")
+	l.Warn().ValidationErrors(errs).Msg("without input")
+	l.Warn().FieldErrors([]FieldError{{Field: "Card", Message: "4111 is not a card number"}}, signupForm{}).Msg("go name")
+
+	entries := decodeLines(t, buf)
+	tests := []struct {
+		entry map[string]interface{}
+		want  map[string]interface{}
+	}{
+		{entries[0], map[string]interface{}{
+			"email":       "must be an email",
+			"card":        redactedValue,
+			"password":    redactedValue,
+			"billing.cvv": redactedValue,
+			"billing.zip": "must have 5 digits",
+		}},
+		{entries[1], map[string]interface{}{
+			"email":       "must be an email",
+			"card":        "4111 is not a card number",
+			"password":    redactedValue,
+			"billing.cvv": "12 must have 3 digits",
+			"billing.zip": "must have 5 digits",
+		}},
+		{entries[2], map[string]interface{}{"Card": redactedValue}},
+	}
+	for _, tt := range tests {
+		if got := tt.entry[KeyValidationErrors]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %s = %v, want %v", tt.entry["message"], KeyValidationErrors, got, tt.want)
+		}
+	}
+}
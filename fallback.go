@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// FallbackWriter is an io.Writer writing to a primary writer, e.g. a log-shipping sidecar,
+// and to a secondary writer the entries the primary failed to write, so they aren't lost.
+type FallbackWriter struct {
+	primary   io.Writer
+	secondary io.Writer
+	failures  atomic.Uint64
+}
+
+// NewFallbackWriter creates a FallbackWriter to primary falling back to secondary, os.Stderr when nil.
+func NewFallbackWriter(primary, secondary io.Writer) *FallbackWriter {
+	if secondary == nil {
+		secondary = os.Stderr
+	}
+	return &FallbackWriter{primary: primary, secondary: secondary}
+}
+
+// Write writes p to the primary writer, or as a whole to the secondary writer when the primary fails,
+// counting the failure in WriteFailures. It only fails when both writers do.
+func (f *FallbackWriter) Write(p []byte) (int, error) {
+	n, err := f.primary.Write(p)
+	if err == nil && n == len(p) {
+		return n, nil
+	}
+
+	f.failures.Add(1)
+	return f.secondary.Write(p)
+}
+
+// WriteFailures returns the number of entries the primary writer failed to write.
+func (f *FallbackWriter) WriteFailures() uint64 {
+	return f.failures.Load()
+}
+
+// Sync commits the entries written to the primary writer, as Logger.Sync.
+func (f *FallbackWriter) Sync() error {
+	switch w := f.primary.(type) {
+	case interface{ Sync() error }:
+		return w.Sync()
+	case interface{ Flush() error }:
+		return w.Flush()
+	}
+	return nil
+}
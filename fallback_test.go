@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// flakyWriter fails the Writes while down is set, and writes to buf otherwise.
+type flakyWriter struct {
+	buf  bytes.Buffer
+	down bool
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.down {
+		return 0, errors.New("sidecar down")
+	}
+	return w.buf.Write(p)
+}
+
+func TestFallbackWriter(t *testing.T) {
+	primary := &flakyWriter{}
+	secondary := &bytes.Buffer{}
+	fw := NewFallbackWriter(primary, secondary)
+	l := NewLogger("test", WithWriter(fw))
+
+	l.Info().Msg("first")
+	primary.down = true
+	l.Info().Msg("second")
+	l.Info().Msg("third")
+	primary.down = false
+	l.Info().Msg("fourth")
+
+	if got := primary.buf.String(); !strings.Contains(got, "first") || !strings.Contains(got, "fourth") || strings.Contains(got, "second") {
+		t.Errorf("primary got %s, want the entries written while up", got)
+	}
+	if got := secondary.String(); strings.Count(got, "\n") != 2 || !strings.Contains(got, "second") || !strings.Contains(got, "third") {
+		t.Errorf("secondary got %s, want the entries the primary failed to write", got)
+	}
+	if got := fw.WriteFailures(); got != 2 {
+		t.Errorf("WriteFailures = %d, want 2", got)
+	}
+}
+
+func TestFallbackWriterBothFail(t *testing.T) {
+	fw := NewFallbackWriter(&flakyWriter{down: true}, &flakyWriter{down: true})
+
+	if _, err := fw.Write([]byte("lost\n")); err == nil {
+		t.Error("got no error, want the error of the secondary writer")
+	}
+	if got := fw.WriteFailures(); got != 1 {
+		t.Errorf("WriteFailures = %d, want 1", got)
+	}
+}
+
+func TestFallbackWriterSync(t *testing.T) {
+	primary := &syncRecorder{}
+	fw := NewFallbackWriter(primary, nil)
+
+	if err := fw.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if !primary.synced {
+		t.Error("primary not synced")
+	}
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetFiberReqEncrLog encrypts and sets the request body in the Fiber locals and user context for logging.
+func SetFiberReqEncrLog(c *fiber.Ctx, req interface{}) {
+	if str, ok := encryptRequestBody(c.UserContext(), req); ok {
+		c.Locals(requestBodyKey, str)
+		c.SetUserContext(WithRequestBody(c.UserContext(), str))
+	}
+}
+
+// SetFiberRespEncrLog encrypts and sets the response body in the Fiber locals and user context for logging.
+func SetFiberRespEncrLog(c *fiber.Ctx, resp interface{}) {
+	if str, ok := encryptResponseData(c.UserContext(), resp); ok {
+		c.Locals(responseBodyKey, str)
+		c.SetUserContext(WithResponseBody(c.UserContext(), str))
+	}
+}
+
+// FiberLoggerMiddleware returns a Fiber middleware logging each request on completion with its method,
+// path, status and latency, along with the bodies set by SetFiberReqEncrLog and SetFiberRespEncrLog.
+// The user context carries the trace information read from the headers as by TraceInfoFromHeader, and its
+// request ID is logged and sent back under the first request ID header set by SetTraceHeaderNames.
+func FiberLoggerMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		// the header values are only valid until the handler returns
+		traceInfo := traceInfoFrom(func(name string) string { return strings.Clone(c.Get(name)) })
+		c.SetUserContext(MergeTraceInfo(c.UserContext(), traceInfo))
+		c.Set(GetTraceHeaderNames().RequestID[0], traceInfo.RequestID)
+
+		err := c.Next()
+
+		l := globalLogger()
+		status := c.Response().StatusCode()
+		e := l.Info()
+		if err != nil {
+			// the error handler runs after the middlewares and sets the status from the error
+			status = fiber.StatusInternalServerError
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				status = fe.Code
+			}
+			e = l.Error().Err(err)
+		}
+
+		names := GetFieldNames()
+		e = e.Str(KeyMethod, c.Method()).
+			Str(KeyPath, c.Path()).
+			Str(KeyRequestID, traceInfo.RequestID).
+			Int(KeyStatus, status).
+			Latency(start)
+		if body, ok := c.Locals(requestBodyKey).(string); ok {
+			e = e.Str(names.RequestBody, body)
+		}
+		if body, ok := c.Locals(responseBodyKey).(string); ok {
+			e = e.Str(names.ResponseBody, body)
+		}
+		e.Msg("http request")
+
+		return err
+	}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type fiberBody struct {
+	Card string `encrypt:"true"`
+	Name string
+}
+
+func TestFiberLoggerMiddleware(t *testing.T) {
+	setTestKey(t)
+	l, buf := newTestLogger()
+	setGlobalLogger(t, l)
+
+	app := fiber.New()
+	app.Use(FiberLoggerMiddleware())
+	app.Post("/pay", func(c *fiber.Ctx) error {
+		SetFiberReqEncrLog(c, fiberBody{Card: "4111", Name: "req"})
+		SetFiberRespEncrLog(c, struct{ Data fiberBody }{fiberBody{Card: "5500", Name: "resp"}})
+		if body, ok := RequestBodyFromContext(c.UserContext()); !ok || body == "" {
+			t.Error("no request body in the user context")
+		}
+		return c.SendStatus(fiber.StatusCreated)
+	})
+	app.Get("/teapot", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusTeapot, "teapot")
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/pay", nil)
+	req.Header.Set("X-Request-ID", "rid-1")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Header.Get("X-Request-ID"); got != "rid-1" {
+		t.Errorf("request ID header = %q, want rid-1 sent back", got)
+	}
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/teapot", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	pay, teapot := entries[0], entries[1]
+	if pay[KeyMethod] != fiber.MethodPost || pay[KeyPath] != "/pay" || pay[KeyStatus] != float64(fiber.StatusCreated) || pay[KeyRequestID] != "rid-1" {
+		t.Errorf("pay entry = %v, want the method, path, status and request ID", pay)
+	}
+	if _, ok := pay[KeyLatency]; !ok {
+		t.Errorf("pay entry = %v, want the latency", pay)
+	}
+	for key, want := range map[string]string{KeyRequestBody: "4111", KeyResponseBody: "5500"} {
+		var body fiberBody
+		if err := json.Unmarshal([]byte(pay[key].(string)), &body); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+		if mustDecrypt(t, body.Card) != want {
+			t.Errorf("%s card = %q, want a ciphertext of %s", key, body.Card, want)
+		}
+	}
+	if teapot["level"] != "error" || teapot[KeyStatus] != float64(fiber.StatusTeapot) {
+		t.Errorf("teapot entry = %v, want the status of the error", teapot)
+	}
+}
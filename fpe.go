@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// TagValFPE is the tag value marking the digit string fields encrypted with
+// format-preserving encryption, e.g. `encrypt:"fpe"`, selecting the same string,
+// *string and []string fields as the encrypt tag value.
+const TagValFPE = "fpe"
+
+const (
+	fpeRadix  = 10
+	fpeRounds = 10
+	// fpeMinLen is the minimum input length, so that radix^minLen >= 1,000,000 as required by FF1.
+	fpeMinLen = 6
+)
+
+// EncryptFPE encrypts a string of decimal digits with the FF1 format-preserving
+// encryption scheme (NIST SP 800-38G), so the ciphertext is a digit string of the same length.
+func EncryptFPE(plaintext, secretKeyHex string) (string, error) {
+	return ff1(plaintext, secretKeyHex, false)
+}
+
+// DecryptFPE decrypts a digit string encrypted by EncryptFPE.
+func DecryptFPE(ciphertext, secretKeyHex string) (string, error) {
+	return ff1(ciphertext, secretKeyHex, true)
+}
+
+// ff1 runs the FF1 Feistel rounds over the digits of x with an empty tweak.
+func ff1(x, secretKeyHex string, decrypt bool) (string, error) {
+	if x == "" {
+		return "", nil
+	}
+
+	n := len(x)
+	if n < fpeMinLen {
+		return "", errors.New("fpe: input must have at least 6 digits")
+	}
+	if strings.IndexFunc(x, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return "", errors.New("fpe: input must only contain decimal digits")
+	}
+
+	secretKey, err := hex.DecodeString(secretKeyHex)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(secretKey)
+	if err != nil {
+		return "", err
+	}
+
+	u := n / 2
+	v := n - u
+	a, b := x[:u], x[u:]
+
+	radix := big.NewInt(fpeRadix)
+	bLen := (new(big.Int).Exp(radix, big.NewInt(int64(v)), nil).BitLen() + 7) / 8
+	dLen := 4*((bLen+3)/4) + 4
+
+	p := []byte{1, 2, 1, 0, 0, fpeRadix, 10, byte(u % 256), 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(p[8:12], uint32(n))
+
+	modU := new(big.Int).Exp(radix, big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(radix, big.NewInt(int64(v)), nil)
+
+	for r := 0; r < fpeRounds; r++ {
+		i := r
+		if decrypt {
+			i = fpeRounds - 1 - r
+		}
+
+		// the round function takes B when encrypting and A when decrypting
+		src := b
+		if decrypt {
+			src = a
+		}
+		y := ff1Round(block, p, i, bLen, dLen, src)
+
+		m, mod := u, modU
+		if i%2 == 1 {
+			m, mod = v, modV
+		}
+
+		if !decrypt {
+			c := numRadix(a)
+			c.Add(c, y).Mod(c, mod)
+			a, b = b, strRadix(c, m)
+		} else {
+			c := numRadix(b)
+			c.Sub(c, y).Mod(c, mod)
+			a, b = strRadix(c, m), a
+		}
+	}
+
+	return a + b, nil
+}
+
+// ff1Round computes the integer y of round i from the digit string src.
+func ff1Round(block cipher.Block, p []byte, i, bLen, dLen int, src string) *big.Int {
+	pad := ((-bLen-1)%aes.BlockSize + aes.BlockSize) % aes.BlockSize
+	q := make([]byte, pad+1+bLen)
+	q[pad] = byte(i)
+	numRadix(src).FillBytes(q[pad+1:])
+
+	// R = PRF(P || Q), a CBC-MAC with a zero IV
+	r := make([]byte, aes.BlockSize)
+	for _, chunk := range [][]byte{p, q} {
+		for j := 0; j < len(chunk); j += aes.BlockSize {
+			for k := 0; k < aes.BlockSize; k++ {
+				r[k] ^= chunk[j+k]
+			}
+			block.Encrypt(r, r)
+		}
+	}
+
+	// S = R || CIPH(R xor [1]) || CIPH(R xor [2]) ... truncated to d bytes
+	s := append([]byte(nil), r...)
+	for j := 1; len(s) < dLen; j++ {
+		ctr := make([]byte, aes.BlockSize)
+		binary.BigEndian.PutUint64(ctr[8:], uint64(j))
+		for k := range ctr {
+			ctr[k] ^= r[k]
+		}
+		block.Encrypt(ctr, ctr)
+		s = append(s, ctr...)
+	}
+
+	return new(big.Int).SetBytes(s[:dLen])
+}
+
+// numRadix returns the number represented by the decimal digit string x.
+func numRadix(x string) *big.Int {
+	num, _ := new(big.Int).SetString(x, fpeRadix)
+	return num
+}
+
+// strRadix returns the m digits decimal representation of num, zero padded.
+func strRadix(num *big.Int, m int) string {
+	str := num.Text(fpeRadix)
+	if len(str) < m {
+		str = strings.Repeat("0", m-len(str)) + str
+	}
+	return str
+}
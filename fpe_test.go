@@ -0,0 +1,94 @@
+package logger
+
+import "testing"
+
+// the radix 10 samples of NIST FF1 with an empty tweak, for AES-128, AES-192 and AES-256
+var ff1Vectors = []struct {
+	key, plaintext, ciphertext string
+}{
+	{"2B7E151628AED2A6ABF7158809CF4F3C", "0123456789", "2433477484"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "0123456789", "2830668132"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "0123456789", "6657667009"},
+}
+
+func TestFF1Vectors(t *testing.T) {
+	for _, v := range ff1Vectors {
+		ciphertext, err := EncryptFPE(v.plaintext, v.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ciphertext != v.ciphertext {
+			t.Errorf("EncryptFPE with a %d bytes key = %s, want %s", len(v.key)/2, ciphertext, v.ciphertext)
+		}
+
+		plaintext, err := DecryptFPE(v.ciphertext, v.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plaintext != v.plaintext {
+			t.Errorf("DecryptFPE with a %d bytes key = %s, want %s", len(v.key)/2, plaintext, v.plaintext)
+		}
+	}
+}
+
+func TestEncryptFPEPreservesFormat(t *testing.T) {
+	for _, pan := range []string{"4111111111111111", "000000", "1234567"} {
+		ciphertext, err := EncryptFPE(pan, testKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ciphertext) != len(pan) || ciphertext == pan {
+			t.Errorf("EncryptFPE(%s) = %s, want another digit string of the same length", pan, ciphertext)
+		}
+		if plaintext, err := DecryptFPE(ciphertext, testKey); err != nil || plaintext != pan {
+			t.Errorf("DecryptFPE(%s) = %s, %v, want %s", ciphertext, plaintext, err, pan)
+		}
+	}
+}
+
+func TestEncryptFPEInvalidInput(t *testing.T) {
+	for _, input := range []string{"12345", "4111-1111-1111", "41111111111a"} {
+		if _, err := EncryptFPE(input, testKey); err == nil {
+			t.Errorf("EncryptFPE(%q) succeeded, want an error", input)
+		}
+	}
+	if ciphertext, err := EncryptFPE("", testKey); ciphertext != "" || err != nil {
+		t.Errorf("EncryptFPE(\"\") = %q, %v, want an empty string", ciphertext, err)
+	}
+}
+
+func TestStructEncryptTagFPE(t *testing.T) {
+	type card struct {
+		PAN    string   `encrypt:"fpe"`
+		Backup *string  `encrypt:"fpe"`
+		Olds   []string `encrypt:"fpe"`
+		Name   string   `encrypt:"true"`
+	}
+	backup := "5500000000000004"
+	input := card{PAN: "4111111111111111", Backup: &backup, Olds: []string{"340000000000009"}, Name: "bob"}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []struct{ plaintext, ciphertext string }{
+		{input.PAN, encrypted.PAN},
+		{backup, *encrypted.Backup},
+		{input.Olds[0], encrypted.Olds[0]},
+	} {
+		if len(got.ciphertext) != len(got.plaintext) || got.ciphertext == got.plaintext || IsEncrypted(got.ciphertext) {
+			t.Errorf("fpe field %s = %s, want a digit string of the same length", got.plaintext, got.ciphertext)
+		}
+	}
+	if mustDecrypt(t, encrypted.Name) != "bob" {
+		t.Errorf("name = %q, want a ciphertext of bob", encrypted.Name)
+	}
+
+	decrypted, err := StructDecryptTag(encrypted, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.PAN != input.PAN || *decrypted.Backup != backup || decrypted.Olds[0] != input.Olds[0] || decrypted.Name != "bob" {
+		t.Errorf("decrypted = %+v, want the plaintexts back", decrypted)
+	}
+}
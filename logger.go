@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
+	"math/rand/v2"
+	"os"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
@@ -17,9 +22,12 @@ import (
 
 // Global logger instance and encryption key
 var (
-	loggerInstance *Logger
+	loggerInstance atomic.Pointer[Logger] // read without locking mu by GetLogger
 	mu             sync.RWMutex
 	keyEncrypt     *string
+	fieldNames     = defaultFieldNames()
+	encryptTagName = TagNameEncrypt
+	encryptTagVal  = TagValEncrypt
 )
 
 // Common constants
@@ -30,14 +38,18 @@ const (
 
 // Logger is the main struct for logging, wrapping zerolog.Logger.
 type Logger struct {
-	logger zerolog.Logger
+	logger  zerolog.Logger
+	w       io.Writer   // writer the logger outputs to, nil when unknown
+	names   *FieldNames // field names of the logger, nil to use the global ones
+	service string      // service name added to the events, empty when none
 }
 
 // InitLog initializes the global logger instance with the given service name.
-func InitLog(serviceName string) {
+// Field names set with WithFieldNames apply to the whole package.
+func InitLog(serviceName string, opts ...Option) {
 	mu.Lock()
 	defer mu.Unlock()
-	if loggerInstance != nil {
+	if loggerInstance.Load() != nil {
 		return
 	}
 
@@ -45,9 +57,84 @@ func InitLog(serviceName string) {
 		log.Fatal().Msg("services name is empty")
 	}
 
+	o := newOptions(opts...)
+	if o.names != nil {
+		fieldNames = *o.names
+	}
+
+	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
+	lg := log.Logger
+
+	var w io.Writer
+	if o.hasOutput() {
+		w = o.output(os.Stderr, fieldNames)
+		lg = lg.Output(w)
+	}
+	loggerInstance.Store(&Logger{logger: o.apply(lg, fieldNames), w: w, service: serviceName})
+}
+
+// NewLogger creates an independent logger with the given service name.
+// Unlike InitLog, it does not touch the global logger instance.
+func NewLogger(serviceName string, opts ...Option) *Logger {
+	if serviceName == "" {
+		log.Fatal().Msg("services name is empty")
+	}
+
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
-	lg := log.With().Str(KeyServiceName, serviceName).Logger()
-	loggerInstance = &Logger{lg}
+	o := newOptions(opts...)
+	l := &Logger{names: o.names, service: serviceName}
+	l.w = o.output(os.Stderr, l.fieldNames())
+	lg := zerolog.New(l.w).With().Timestamp().Logger()
+	l.logger = o.apply(lg, l.fieldNames())
+	return l
+}
+
+// NewNoopLogger creates a logger discarding every event, e.g. for tests or as a safe default.
+// It doesn't touch the global logger instance.
+func NewNoopLogger() *Logger {
+	return &Logger{logger: zerolog.Nop()}
+}
+
+// ParseLevel returns the level named s, case-insensitively: trace, debug, info, warn (or warning),
+// error, fatal, panic or disabled.
+func ParseLevel(s string) (zerolog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return zerolog.TraceLevel, nil
+	case "debug":
+		return zerolog.DebugLevel, nil
+	case "info":
+		return zerolog.InfoLevel, nil
+	case "warn", "warning":
+		return zerolog.WarnLevel, nil
+	case "error":
+		return zerolog.ErrorLevel, nil
+	case "fatal":
+		return zerolog.FatalLevel, nil
+	case "panic":
+		return zerolog.PanicLevel, nil
+	case "disabled":
+		return zerolog.Disabled, nil
+	}
+	return zerolog.NoLevel, fmt.Errorf("unknown log level %q, want one of trace, debug, info, warn, error, fatal, panic or disabled", s)
+}
+
+// SetLevelFromString sets the level of the global logger instance to the level named s, see ParseLevel.
+func SetLevelFromString(s string) error {
+	lvl, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	l := loggerInstance.Load()
+	if l == nil {
+		return fmt.Errorf("logger is not initialized, call InitLog first")
+	}
+	newL := l.Level(lvl)
+	loggerInstance.Store(&newL)
+	return nil
 }
 
 // SetKeyEncrypt sets the encryption key for logging.
@@ -57,39 +144,146 @@ func SetKeyEncrypt(key string) {
 	}
 }
 
+// HasKeyEncrypt reports whether an encryption key is set by SetKeyEncrypt, e.g. to log a payload
+// only when it can be encrypted.
+func HasKeyEncrypt() bool {
+	return keyEncrypt != nil && *keyEncrypt != ""
+}
+
+var encryptionDisabled atomic.Bool
+
+// SetEncryptionEnabled sets whether EncryptLog, EncryptInterface, the struct walkers and the Echo
+// and Fiber setters encrypt, e.g. to read the payloads in local development. When disabled, they
+// return their input unchanged while the key stays set. Encryption is enabled by default.
+func SetEncryptionEnabled(enabled bool) {
+	encryptionDisabled.Store(!enabled)
+}
+
+// SetKeyEncryptValidated is SetKeyEncrypt checking the key with ValidateKey first,
+// so a malformed key fails at startup rather than on the first encrypted field.
+// The key isn't set when it is invalid.
+func SetKeyEncryptValidated(key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	SetKeyEncrypt(key)
+	return nil
+}
+
+// SetDefaultEncryptTag sets the tag `tagName:"tagVal"` marking the fields encrypted
+// by EncryptLog, EncryptInterface and the Echo setters. It defaults to `encrypt:"true"`.
+func SetDefaultEncryptTag(tagName, tagVal string) {
+	mu.Lock()
+	defer mu.Unlock()
+	encryptTagName = tagName
+	encryptTagVal = tagVal
+}
+
+// defaultEncryptTag returns the tag name and value set by SetDefaultEncryptTag.
+func defaultEncryptTag() (string, string) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return encryptTagName, encryptTagVal
+}
+
+// GetFieldNames returns the field names set at InitLog.
+func GetFieldNames() FieldNames {
+	mu.RLock()
+	defer mu.RUnlock()
+	return fieldNames
+}
+
 // GetLogger returns the global logger instance.
 func GetLogger() *Logger {
-	return loggerInstance
+	return loggerInstance.Load()
 }
 
-// SetEchoReqEncrLog encrypts and sets the request body in Echo context for logging.
-func SetEchoReqEncrLog(c echo.Context, req interface{}) {
-	if keyEncrypt == nil || *keyEncrypt == "" {
-		return
+// globalLogger returns the global logger instance, or zerolog's global logger before InitLog.
+func globalLogger() *Logger {
+	if l := GetLogger(); l != nil {
+		return l
 	}
+	return &Logger{logger: log.Logger}
+}
 
-	ctx := c.Request().Context()
-	if req != nil {
-		if newReq, err := StructEncryptTagInterface(req, *keyEncrypt, TagNameEncrypt, TagValEncrypt); err == nil {
-			if str, err := AnyToString(newReq); err == nil {
-				ctx = context.WithValue(ctx, KeyRequestBody, str)
-				c.SetRequest(c.Request().WithContext(ctx))
-			}
-		}
+// FromZerolog wraps an existing zerolog.Logger in a Logger.
+func FromZerolog(zl zerolog.Logger) Logger {
+	return Logger{logger: zl}
+}
+
+// SetEchoReqEncrLog encrypts and sets the request body in Echo context for logging.
+func SetEchoReqEncrLog(c echo.Context, req interface{}) {
+	if str, ok := encryptRequestBody(c.Request().Context(), req); ok {
+		ctx := WithRequestBody(c.Request().Context(), str)
+		c.SetRequest(c.Request().WithContext(ctx))
 	}
 }
 
 // SetEchoRespEncrLog encrypts and sets the response body in Echo context for logging.
+// The logged value is the field set by SetResponseDataField, or the whole response
+// when it has none and EncryptWholeResponse is enabled.
 func SetEchoRespEncrLog(c echo.Context, resp interface{}) {
-	if keyEncrypt == nil || *keyEncrypt == "" {
-		return
+	if str, ok := encryptResponseData(c.Request().Context(), resp); ok {
+		ctx := WithResponseBody(c.Request().Context(), str)
+		c.SetRequest(c.Request().WithContext(ctx))
+	}
+}
+
+// encryptRequestBody returns the string of req with its tagged fields encrypted, to store for logging.
+// It reports false when no key is set or req can't be encrypted, including when ctx is done.
+func encryptRequestBody(ctx context.Context, req interface{}) (string, bool) {
+	if keyEncrypt == nil || *keyEncrypt == "" || req == nil {
+		return "", false
+	}
+
+	tagName, tagVal := defaultEncryptTag()
+	newReq, err := StructEncryptTagContext(ctx, req, *keyEncrypt, tagName, tagVal)
+	if err != nil {
+		return "", false
 	}
 
-	ctx := c.Request().Context()
+	str, err := anyToString(newReq)
+	return truncateEncrypted(str), err == nil
+}
 
-	// check response is nil
-	if resp == nil {
-		return
+// DefaultResponseDataField is the default name of the field of the responses holding their data.
+const DefaultResponseDataField = "Data"
+
+var (
+	responseDataField    atomic.Pointer[string]
+	encryptWholeResponse atomic.Bool
+)
+
+// SetResponseDataField sets the name of the field of the responses whose value the response setters
+// encrypt and store, DefaultResponseDataField by default. An empty name restores the default.
+func SetResponseDataField(name string) {
+	if name == "" {
+		name = DefaultResponseDataField
+	}
+	responseDataField.Store(&name)
+}
+
+// EncryptWholeResponse sets whether the response setters encrypt and store the whole response
+// when it has no field named as set by SetResponseDataField. By default, nothing is stored then.
+func EncryptWholeResponse(enabled bool) {
+	encryptWholeResponse.Store(enabled)
+}
+
+// currentResponseDataField returns the field name set by SetResponseDataField.
+func currentResponseDataField() string {
+	if name := responseDataField.Load(); name != nil {
+		return *name
+	}
+	return DefaultResponseDataField
+}
+
+// encryptResponseData returns the string of the data field of resp with its tagged fields encrypted,
+// to store for logging, or of the whole resp as set by EncryptWholeResponse.
+// It reports false when no key is set or resp has nothing to encrypt.
+func encryptResponseData(ctx context.Context, resp interface{}) (string, bool) {
+	if keyEncrypt == nil || *keyEncrypt == "" || resp == nil {
+		return "", false
 	}
 
 	v := reflect.ValueOf(resp)
@@ -97,21 +291,59 @@ func SetEchoRespEncrLog(c echo.Context, resp interface{}) {
 		v = v.Elem()
 	}
 
-	// get value field Data from response
+	// get value of the data field from response
+	var data reflect.Value
 	if v.Kind() == reflect.Struct {
-		if data := v.FieldByName("Data"); data.IsValid() {
-			if data.Kind() == reflect.Ptr {
-				data = data.Elem()
-			}
-
-			if newRes, err := InterfaceEncryptTagInterface(data.Interface(), *keyEncrypt, TagNameEncrypt, TagValEncrypt); err == nil {
-				if str, err := AnyToString(newRes); err == nil {
-					ctx = context.WithValue(ctx, KeyResponseBody, str)
-					c.SetRequest(c.Request().WithContext(ctx))
-				}
-			}
+		data = v.FieldByName(currentResponseDataField())
+	}
+	// an unexported field can't be read, like a missing one
+	if !data.IsValid() || !data.CanInterface() {
+		if !encryptWholeResponse.Load() {
+			return "", false
+		}
+		data = reflect.ValueOf(resp)
+	}
+	if data.Kind() == reflect.Ptr {
+		if data.IsNil() {
+			return "", false
 		}
+		data = data.Elem()
+	}
+
+	tagName, tagVal := defaultEncryptTag()
+	newRes, err := InterfaceEncryptTagContext(ctx, data.Interface(), *keyEncrypt, tagName, tagVal)
+	if err != nil {
+		return "", false
 	}
+
+	str, err := anyToString(newRes)
+	return truncateEncrypted(str), err == nil
+}
+
+// GetEchoReqDecrLog returns the request body stored by SetEchoReqEncrLog with its encrypted values decrypted.
+// It returns an empty string when nothing is stored.
+func GetEchoReqDecrLog(c echo.Context) (string, error) {
+	return decryptStoredBody(RequestBodyFromContext(c.Request().Context()))
+}
+
+// GetEchoRespDecrLog returns the response body stored by SetEchoRespEncrLog with its encrypted values decrypted.
+// It returns an empty string when nothing is stored.
+func GetEchoRespDecrLog(c echo.Context) (string, error) {
+	return decryptStoredBody(ResponseBodyFromContext(c.Request().Context()))
+}
+
+// decryptStoredBody decrypts the values of a body stored in context by the Echo setters.
+// Since the Go type of the body is gone, every string value that is a valid ciphertext is decrypted.
+func decryptStoredBody(body string, ok bool) (string, error) {
+	if !ok || body == "" {
+		return "", nil
+	}
+
+	if keyEncrypt == nil || *keyEncrypt == "" {
+		return body, ErrNoKey
+	}
+
+	return decryptJSONValues(body, *keyEncrypt)
 }
 
 // ------------------- Logger -------------------
@@ -119,38 +351,81 @@ func SetEchoRespEncrLog(c echo.Context, resp interface{}) {
 // StackTrace adds stacktrace information to the logger and returns a new logger.
 func (l *Logger) StackTrace() *Logger {
 	stack := GetFullStack()
-	newLg := l.logger.With().Str(KeyFileError, stack).Logger()
-	return &Logger{newLg}
+	newLg := l.derive(l.logger.With().Str(l.fieldNames().FileError, stack).Logger())
+	return &newLg
+}
+
+// WithFields returns a new logger adding each entry of fields to its events, in key order.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	newL := l.derive(l.logger.With().Fields(fields).Logger())
+	return &newL
 }
 
 // AddTraceInfoContextRequest adds trace and caller information from context to the logger.
+// Each baggage entry of the trace information is added as a field of its own,
+// followed by the context values registered with RegisterContextField.
 func (l *Logger) AddTraceInfoContextRequest(ctx context.Context) *Logger {
-	newLg := l.logger.With().Interface("caller", l.GetCaller()).Logger()
+	return l.addTraceInfo(ctx, l.GetCaller())
+}
+
+// WithContextFields returns a new logger adding the values of ctx registered with RegisterContextField.
+func (l *Logger) WithContextFields(ctx context.Context) *Logger {
+	newL := l.derive(addContextFields(l.logger.With(), ctx).Logger())
+	return &newL
+}
+
+// addTraceInfo adds the trace information from ctx and caller to the logger.
+func (l *Logger) addTraceInfo(ctx context.Context, caller string) *Logger {
+	newLg := l.logger.With().Interface("caller", caller).Logger()
 	traceInfo := GetRequestIdByContext(ctx)
 	if traceInfo != nil {
-		newLg = newLg.With().Interface(KeyTraceInfo, traceInfo).Logger()
+		baggage := traceInfo.Baggage
+		traceInfo.Baggage = nil
+		c := newLg.With().Interface(KeyTraceInfo, traceInfo)
+		for _, k := range slices.Sorted(maps.Keys(baggage)) {
+			c = c.Str(k, baggage[k])
+		}
+		newLg = c.Logger()
 	}
-	return &Logger{newLg}
+	newLg = addContextFields(newLg.With(), ctx).Logger()
+	newL := l.derive(newLg)
+	return &newL
 }
 
 // Output returns a new logger that writes to writer w.
 func (l Logger) Output(w io.Writer) Logger {
-	return Logger{l.logger.Output(w)}
+	newL := l.derive(l.logger.Output(w))
+	newL.w = w
+	return newL
 }
 
 // Level returns a new logger with the specified level.
 func (l Logger) Level(lvl zerolog.Level) Logger {
-	return Logger{l.logger.Level(lvl)}
+	return l.derive(l.logger.Level(lvl))
+}
+
+// WithLevelScope returns a child logger at level, e.g. zerolog.DebugLevel to investigate a code path,
+// and a func ending the scope, after which the child logs at the level of l again.
+// The level of l and of the other loggers is left as is, but no logger goes below zerolog.GlobalLevel.
+func (l *Logger) WithLevelScope(level zerolog.Level) (*Logger, func()) {
+	parent := l.logger.GetLevel()
+	var ended atomic.Bool
+	newL := l.derive(l.logger.Level(level).Hook(zerolog.HookFunc(func(e *zerolog.Event, lvl zerolog.Level, _ string) {
+		if ended.Load() && lvl < parent {
+			e.Discard()
+		}
+	})))
+	return &newL, func() { ended.Store(true) }
 }
 
 // Sample returns a new logger with the specified sampler.
 func (l Logger) Sample(s zerolog.Sampler) Logger {
-	return Logger{l.logger.Sample(s)}
+	return l.derive(l.logger.Sample(s))
 }
 
 // Hook returns a new logger with the specified hooks.
 func (l Logger) Hook(hooks ...zerolog.Hook) Logger {
-	return Logger{l.logger.Hook(hooks...)}
+	return l.derive(l.logger.Hook(hooks...))
 }
 
 // ------------------- Logger -------------------
@@ -162,67 +437,243 @@ func (l Logger) With() Context {
 	return Context{l: l}
 }
 
+// WithServiceName returns a child logger whose events carry name as service name instead of l's,
+// e.g. for the jobs a worker runs for other services. It replaces the service name set by InitLog,
+// NewLogger or WithServiceName, not a service field added to a zerolog context, e.g. through FromZerolog.
+func (l Logger) WithServiceName(name string) Logger {
+	l.service = name
+	return l
+}
+
+// withService returns the zerolog logger of l adding the service name of l to its events,
+// for the writes not going through newEvent.
+func (l Logger) withService() zerolog.Logger {
+	if l.service == "" {
+		return l.logger
+	}
+	return l.logger.With().Str(l.fieldNames().Service, l.service).Logger()
+}
+
+// newEvent wraps e, adding the service name of l, if any.
+func (l *Logger) newEvent(e *zerolog.Event) *Event {
+	if l.service != "" {
+		e.Str(l.fieldNames().Service, l.service)
+	}
+	return &Event{event: e, names: l.names}
+}
+
+// Str returns a child logger adding the field key with the string val to its events.
+func (l Logger) Str(key, val string) Logger {
+	return l.With().Str(key, val).Logger()
+}
+
+// Int returns a child logger adding the field key with the int val to its events.
+func (l Logger) Int(key string, val int) Logger {
+	return l.With().Int(key, val).Logger()
+}
+
+// Bool returns a child logger adding the field key with the bool val to its events.
+func (l Logger) Bool(key string, val bool) Logger {
+	return l.With().Bool(key, val).Logger()
+}
+
 // ------------------- Context -------------------
 
 // ------------------- context.Context -------------------
 
 // WithContext returns a context containing this logger.
 func (l Logger) WithContext(ctx context.Context) context.Context {
-	return l.logger.WithContext(ctx)
+	zl := l.withService()
+	return zl.WithContext(ctx)
+}
+
+// Ctx returns the logger stored in ctx by WithContext.
+// It falls back to the global logger instance when ctx carries no enabled logger.
+func Ctx(ctx context.Context) *Logger {
+	zl := zerolog.Ctx(ctx)
+	if zl.GetLevel() == zerolog.Disabled {
+		if lg := GetLogger(); lg != nil {
+			return lg
+		}
+	}
+	return &Logger{logger: *zl}
 }
 
 // ------------------- context.Context -------------------
 
+var (
+	secureStrict    atomic.Bool
+	secureNoKeyOnce sync.Once
+)
+
+// SetSecureStrict sets whether InfoSecure leaves the payload out of its events when no key is set,
+// rather than logging it in plaintext. It is disabled by default.
+func SetSecureStrict(enabled bool) {
+	secureStrict.Store(enabled)
+}
+
+// InfoSecure logs msg at Info level with obj under KeyPayload, its tagged fields encrypted
+// as by EncryptInterface and serialized by AnyToString. When no key is set, the payload is
+// logged in plaintext with a warning on the first call, or left out in strict mode.
+func (l *Logger) InfoSecure(msg string, obj interface{}) {
+	e := l.Info()
+	if e.event == nil {
+		return
+	}
+
+	if keyEncrypt == nil || *keyEncrypt == "" {
+		if secureStrict.Load() {
+			e.Msg(msg)
+			return
+		}
+		secureNoKeyOnce.Do(func() {
+			l.Warn().Msg("no encryption key set, InfoSecure logs the payloads in plaintext")
+		})
+	}
+
+	payload := encryptErrValue
+	if encrypted, err := EncryptInterface(obj); err == nil {
+		if str, err := anyToString(encrypted); err == nil {
+			payload = truncateEncrypted(str)
+		}
+	}
+	e.Str(KeyPayload, payload).Msg(msg)
+}
+
 // ------------------- Event -------------------
 
 // Trace creates a log event at Trace level.
 func (l *Logger) Trace() *Event {
-	return &Event{l.logger.Trace()}
+	return l.newEvent(l.logger.Trace())
 }
 
 // Debug creates a log event at Debug level.
 func (l *Logger) Debug() *Event {
-	return &Event{l.logger.Debug()}
+	return l.newEvent(l.logger.Debug())
 }
 
 // Info creates a log event at Info level.
 func (l *Logger) Info() *Event {
-	return &Event{l.logger.Info()}
+	return l.newEvent(l.logger.Info())
 }
 
 // Warn creates a log event at Warn level.
 func (l *Logger) Warn() *Event {
-	return &Event{l.logger.Warn()}
+	return l.newEvent(l.logger.Warn())
+}
+
+// WarnSampledStack creates a log event at Warn level carrying the stack of the caller
+// under FieldNames.FileError for about rate of the calls, from 0 for none to 1 for all.
+// The stack is costly to collect, sampling it keeps a few traces of intermittent warnings.
+func (l *Logger) WarnSampledStack(rate float64) *Event {
+	e := l.Warn()
+	if e.event == nil || rate <= 0 || (rate < 1 && rand.Float64() >= rate) {
+		return e
+	}
+	e.event.Str(l.fieldNames().FileError, GetFullStack())
+	return e
 }
 
 // Error creates a log event at Error level.
 func (l *Logger) Error() *Event {
-	return &Event{l.logger.Error()}
+	return l.newEvent(l.logger.Error())
 }
 
 // Err creates a log event with the provided error.
 func (l *Logger) Err(err error) *Event {
-	return &Event{l.logger.Err(err)}
+	return l.newEvent(l.logger.Err(err))
 }
 
 // Fatal creates a log event at Fatal level.
 func (l *Logger) Fatal() *Event {
-	return &Event{l.logger.Fatal()}
+	return l.newEvent(l.logger.Fatal())
 }
 
 // Panic creates a log event at Panic level.
 func (l *Logger) Panic() *Event {
-	return &Event{l.logger.Panic()}
+	return l.newEvent(l.logger.Panic())
 }
 
 // WithLevel creates a log event with the specified level.
 func (l *Logger) WithLevel(level zerolog.Level) *Event {
-	return &Event{l.logger.WithLevel(level)}
+	return l.newEvent(l.logger.WithLevel(level))
 }
 
 // Log creates a default log event.
 func (l *Logger) Log() *Event {
-	return &Event{l.logger.Log()}
+	return l.newEvent(l.logger.Log())
+}
+
+// DebugIf creates a log event at Debug level when cond is true, and a no-op event otherwise.
+func (l *Logger) DebugIf(cond bool) *Event {
+	if !cond {
+		return &Event{}
+	}
+	return l.Debug()
+}
+
+// InfoIf creates a log event at Info level when cond is true, and a no-op event otherwise.
+func (l *Logger) InfoIf(cond bool) *Event {
+	if !cond {
+		return &Event{}
+	}
+	return l.Info()
+}
+
+// WarnIf creates a log event at Warn level when cond is true, and a no-op event otherwise.
+func (l *Logger) WarnIf(cond bool) *Event {
+	if !cond {
+		return &Event{}
+	}
+	return l.Warn()
+}
+
+// Debugf logs a message at Debug level formatted with fmt.Sprintf.
+// The message isn't formatted when the level is disabled.
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.Debug().Msgf(format, v...)
+}
+
+// Infof logs a message at Info level formatted with fmt.Sprintf.
+func (l *Logger) Infof(format string, v ...interface{}) {
+	l.Info().Msgf(format, v...)
+}
+
+// Warnf logs a message at Warn level formatted with fmt.Sprintf.
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.Warn().Msgf(format, v...)
+}
+
+// Errorf logs a message at Error level formatted with fmt.Sprintf.
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.Error().Msgf(format, v...)
+}
+
+// InfofCtx logs a message at Info level formatted with fmt.Sprintf, with the trace information
+// of ctx and the caller, as added by AddTraceInfoContextRequest.
+func (l *Logger) InfofCtx(ctx context.Context, format string, v ...interface{}) {
+	l.addTraceInfo(ctx, callerInfo(2)).Info().Msgf(format, v...)
+}
+
+// WarnfCtx is InfofCtx at Warn level.
+func (l *Logger) WarnfCtx(ctx context.Context, format string, v ...interface{}) {
+	l.addTraceInfo(ctx, callerInfo(2)).Warn().Msgf(format, v...)
+}
+
+// ErrorfCtx is InfofCtx at Error level.
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, v ...interface{}) {
+	l.addTraceInfo(ctx, callerInfo(2)).Error().Msgf(format, v...)
+}
+
+// LogErr logs err at Error level with the stack of the caller and msg, then returns err,
+// so that `return l.LogErr(err, "failed to save")` logs and propagates it. A nil err logs nothing.
+func (l *Logger) LogErr(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	l.Error().Err(err).Str(l.fieldNames().FileError, GetFullStack()).Msg(msg)
+	return err
 }
 
 // ------------------- Event -------------------
@@ -231,7 +682,13 @@ func (l *Logger) Log() *Event {
 
 // GetCaller returns the file, line, and function information of the logger caller.
 func (l *Logger) GetCaller() string {
-	pc, file, line, ok := runtime.Caller(2) // Adjust the call stack index as needed
+	return callerInfo(3)
+}
+
+// callerInfo returns the file, line, and function information of the caller skip frames up,
+// as runtime.Caller counting callerInfo itself.
+func callerInfo(skip int) string {
+	pc, file, line, ok := runtime.Caller(skip)
 	if !ok {
 		return ""
 	}
@@ -240,7 +697,64 @@ func (l *Logger) GetCaller() string {
 	parts := strings.Split(fullFnName, ".")
 	fnName := parts[len(parts)-1]
 
-	return fmt.Sprintf("%s:%d %s", file, line, fnName)
+	return fmt.Sprintf("%s:%d %s", trimCallerFile(file, fullFnName), line, fnName)
+}
+
+// fieldNames returns the field names of the logger.
+func (l Logger) fieldNames() FieldNames {
+	if l.names != nil {
+		return *l.names
+	}
+	return GetFieldNames()
+}
+
+// derive returns a copy of the logger using lg, keeping the writer and field names.
+func (l Logger) derive(lg zerolog.Logger) Logger {
+	l.logger = lg
+	return l
+}
+
+// Flush waits until the logger's writer has written all pending entries,
+// if the writer supports flushing (e.g. an AsyncWriter).
+func (l *Logger) Flush() error {
+	if f, ok := l.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Sync commits the entries written so far to the logger's writer, to call before the program exits.
+// It calls the writer's Sync method (e.g. an *os.File) or else its Flush method, when available.
+func (l *Logger) Sync() error {
+	switch w := l.w.(type) {
+	case interface{ Sync() error }:
+		return w.Sync()
+	case interface{ Flush() error }:
+		return w.Flush()
+	}
+	return nil
+}
+
+// Sync syncs the global logger instance, if initialized.
+func Sync() error {
+	if l := GetLogger(); l != nil {
+		return l.Sync()
+	}
+	return nil
+}
+
+// Close flushes and stops the logger's AsyncWriter, if any.
+func (l *Logger) Close() error {
+	if aw, ok := l.w.(*AsyncWriter); ok {
+		return aw.Close()
+	}
+	return nil
+}
+
+// Zerolog returns a copy of the underlying zerolog.Logger.
+// Modifications to the returned copy don't affect the wrapper.
+func (l Logger) Zerolog() zerolog.Logger {
+	return l.withService()
 }
 
 // GetLevel returns the current log level of the logger.
@@ -248,9 +762,16 @@ func (l Logger) GetLevel() zerolog.Level {
 	return l.logger.GetLevel()
 }
 
+// Enabled reports whether the events at level are written by the logger, to guard costly work.
+// The sampler of the logger isn't taken into account.
+func (l *Logger) Enabled(level zerolog.Level) bool {
+	return level != zerolog.Disabled && level >= l.logger.GetLevel() && level >= zerolog.GlobalLevel()
+}
+
 // Write writes a log as []byte.
 func (l Logger) Write(p []byte) (n int, err error) {
-	return l.logger.Write(p)
+	zl := l.withService()
+	return zl.Write(p)
 }
 
 // UpdateContext updates the logger's context.
@@ -260,15 +781,18 @@ func (l *Logger) UpdateContext(update func(c zerolog.Context) zerolog.Context) {
 
 // Print writes a log using Print.
 func (l *Logger) Print(v ...interface{}) {
-	l.logger.Print(v...)
+	zl := l.withService()
+	zl.Print(v...)
 }
 
 // Printf writes a log using Printf.
 func (l *Logger) Printf(format string, v ...interface{}) {
-	l.logger.Printf(format, v...)
+	zl := l.withService()
+	zl.Printf(format, v...)
 }
 
 // Println writes a log using Println.
 func (l *Logger) Println(v ...interface{}) {
-	l.logger.Println(v...)
+	zl := l.withService()
+	zl.Println(v...)
 }
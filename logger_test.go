@@ -0,0 +1,677 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// newTestLogger returns a logger of the service "test" writing its entries to the returned buffer.
+func newTestLogger(opts ...Option) (*Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return NewLogger("test", append(opts, WithWriter(buf))...), buf
+}
+
+// decodeLines decodes the JSON entries written to buf, one per line.
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid entry %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// setGlobalLogger makes l the global logger instance for the duration of the test.
+func setGlobalLogger(t *testing.T, l *Logger) {
+	t.Helper()
+
+	prev := loggerInstance.Swap(l)
+	t.Cleanup(func() { loggerInstance.Store(prev) })
+}
+
+func TestZerologReturnsCopy(t *testing.T) {
+	l, buf := newTestLogger()
+
+	zl := l.Zerolog()
+	zl.UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.Str("extra", "1")
+	})
+	l.Info().Msg("wrapper")
+	zl.Info().Msg("copy")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if _, ok := entries[0]["extra"]; ok {
+		t.Errorf("wrapper entry carries the field added to the copy: %v", entries[0])
+	}
+	if entries[1]["extra"] != "1" || entries[1]["service_name"] != "test" {
+		t.Errorf("copy entry = %v, want extra and service_name", entries[1])
+	}
+}
+
+func TestFromZerolog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := FromZerolog(zerolog.New(buf).With().Str("origin", "zerolog").Logger())
+
+	l.Info().Str("k", "v").Msg("wrapped")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 || entries[0]["origin"] != "zerolog" || entries[0]["k"] != "v" {
+		t.Errorf("entries = %v, want the zerolog context and the event field", entries)
+	}
+}
+
+func TestNewLoggerIsolation(t *testing.T) {
+	before := GetLogger()
+	audit, auditBuf := newTestLogger()
+	appBuf := &bytes.Buffer{}
+	app := NewLogger("app", WithWriter(appBuf))
+
+	audit.Info().Msg("audit entry")
+	app.Info().Msg("app entry")
+
+	auditEntries, appEntries := decodeLines(t, auditBuf), decodeLines(t, appBuf)
+	if len(auditEntries) != 1 || auditEntries[0]["service_name"] != "test" || auditEntries[0]["message"] != "audit entry" {
+		t.Errorf("audit entries = %v", auditEntries)
+	}
+	if len(appEntries) != 1 || appEntries[0]["service_name"] != "app" || appEntries[0]["message"] != "app entry" {
+		t.Errorf("app entries = %v", appEntries)
+	}
+	if GetLogger() != before {
+		t.Error("NewLogger changed the global logger instance")
+	}
+}
+
+func TestCtx(t *testing.T) {
+	l, buf := newTestLogger()
+	ctx := l.With().Str("request_id", "r1").Logger().WithContext(context.Background())
+
+	Ctx(ctx).Info().Msg("scoped")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 || entries[0]["request_id"] != "r1" || entries[0]["service_name"] != "test" {
+		t.Errorf("entries = %v, want the fields of the stored logger", entries)
+	}
+}
+
+func TestCtxFallsBackToGlobalLogger(t *testing.T) {
+	global, buf := newTestLogger()
+	setGlobalLogger(t, global)
+
+	if got := Ctx(context.Background()); got != global {
+		t.Fatalf("Ctx without a stored logger = %p, want the global logger %p", got, global)
+	}
+	Ctx(context.Background()).Info().Msg("global")
+	if entries := decodeLines(t, buf); len(entries) != 1 {
+		t.Errorf("got %d entries, want 1", len(entries))
+	}
+}
+
+func TestWithFieldNames(t *testing.T) {
+	l, buf := newTestLogger(WithFieldNames(FieldNames{Service: "svc", FileError: "error.stack"}))
+
+	l.Info().Msg("renamed")
+	l.StackTrace().Error().Msg("with stack")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0]["svc"] != "test" {
+		t.Errorf("entry = %v, want the service under svc", entries[0])
+	}
+	if _, ok := entries[0][KeyServiceName]; ok {
+		t.Errorf("entry = %v, want no default service field", entries[0])
+	}
+	if _, ok := entries[1]["error.stack"]; !ok {
+		t.Errorf("entry = %v, want the stack under error.stack", entries[1])
+	}
+	if GetFieldNames() != defaultFieldNames() {
+		t.Error("NewLogger changed the global field names")
+	}
+}
+
+func TestLogIf(t *testing.T) {
+	l, buf := newTestLogger(WithLogLevel(zerolog.DebugLevel))
+
+	l.DebugIf(false).Str("k", "v").Msg("skipped")
+	l.InfoIf(false).Int("n", 1).Msgf("skipped %d", 1)
+	l.WarnIf(false).Msg("skipped")
+	if buf.Len() != 0 {
+		t.Fatalf("false conditions wrote %q, want no output", buf.String())
+	}
+
+	l.DebugIf(true).Msg("debug")
+	l.InfoIf(true).Msg("info")
+	l.WarnIf(true).Msg("warn")
+	if got := len(decodeLines(t, buf)); got != 3 {
+		t.Errorf("got %d entries for the true conditions, want 3", got)
+	}
+}
+
+// syncRecorder records the calls to its Sync method.
+type syncRecorder struct {
+	bytes.Buffer
+	synced bool
+}
+
+func (w *syncRecorder) Sync() error {
+	w.synced = true
+	return nil
+}
+
+// flushRecorder records the calls to its Flush method.
+type flushRecorder struct {
+	bytes.Buffer
+	flushed bool
+}
+
+func (w *flushRecorder) Flush() error {
+	w.flushed = true
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	syncer := &syncRecorder{}
+	l := NewLogger("test", WithWriter(syncer))
+	if err := l.Sync(); err != nil || !syncer.synced {
+		t.Errorf("Sync = %v, synced %t, want the writer's Sync called", err, syncer.synced)
+	}
+
+	flusher := &flushRecorder{}
+	l = NewLogger("test", WithWriter(flusher))
+	if err := l.Sync(); err != nil || !flusher.flushed {
+		t.Errorf("Sync = %v, flushed %t, want the writer's Flush called", err, flusher.flushed)
+	}
+
+	global := &syncRecorder{}
+	setGlobalLogger(t, NewLogger("test", WithWriter(global)))
+	if err := Sync(); err != nil || !global.synced {
+		t.Errorf("package Sync = %v, synced %t, want the global writer's Sync called", err, global.synced)
+	}
+}
+
+// assertOrder fails the test unless the parts appear in line in that order.
+func assertOrder(t *testing.T, line string, parts ...string) {
+	t.Helper()
+
+	last := -1
+	for _, part := range parts {
+		i := strings.Index(line, part)
+		if i <= last {
+			t.Fatalf("line %q doesn't render %q in the order %q", line, part, parts)
+		}
+		last = i
+	}
+}
+
+func TestWithConsoleFieldOrder(t *testing.T) {
+	l, buf := newTestLogger(WithConsoleFieldOrder(nil))
+	scoped := l.With().RequestID("r1").Logger()
+	scoped.Info().Str("extra", "x").Msg("hello")
+
+	// the default order: time, level, service, request ID, message, then the other fields
+	assertOrder(t, buf.String(), "INF", "service_name=test", "request_id=r1", "hello", "extra=")
+	if strings.Count(buf.String(), "request_id") != 1 {
+		t.Errorf("line %q repeats the ordered fields", buf.String())
+	}
+
+	l, buf = newTestLogger(WithConsoleFieldOrder([]string{KeyRequestID, zerolog.MessageFieldName, zerolog.LevelFieldName}))
+	scoped = l.With().RequestID("r2").Logger()
+	scoped.Warn().Msg("custom")
+
+	assertOrder(t, buf.String(), "request_id=r2", "custom", "WRN", "service_name=")
+}
+
+func TestNewNoopLogger(t *testing.T) {
+	key := keyEncrypt
+	l := NewNoopLogger()
+
+	l.Info().Str("k", "v").EncStr("pan", "4111").Interface("obj", map[string]int{"a": 1}).Msg("dropped")
+	l.Error().Err(errors.New("boom")).Stack().Msgf("dropped %d", 1)
+	l.WarnIf(true).Latency(time.Now()).Send()
+	l.Infof("dropped %s", "too")
+	l.InfoSecure("dropped", struct{ A string }{"a"})
+	l.StackTrace().WithFields(map[string]interface{}{"a": 1}).Info().Msg("dropped")
+	child := l.With().RequestID("r1").Logger()
+	child.Debug().Msg("dropped")
+
+	for _, lg := range []*Logger{l, &child} {
+		if lg.Enabled(zerolog.PanicLevel) {
+			t.Errorf("logger enabled at panic level, want every level disabled")
+		}
+	}
+	if err := l.Sync(); err != nil {
+		t.Errorf("Sync = %v, want nil", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("Close = %v, want nil", err)
+	}
+	if keyEncrypt != key {
+		t.Error("NewNoopLogger changed the encryption key")
+	}
+}
+
+func TestGetLoggerDuringReconfigure(t *testing.T) {
+	setGlobalLogger(t, NewLogger("test", WithWriter(io.Discard)))
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					GetLogger().Info().Msg("read")
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		level := "debug"
+		if i%2 == 1 {
+			level = "info"
+		}
+		if err := SetLevelFromString(level); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+func BenchmarkGetLogger(b *testing.B) {
+	l := NewLogger("test", WithWriter(io.Discard))
+	prev := loggerInstance.Swap(l)
+	b.Cleanup(func() { loggerInstance.Store(prev) })
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = GetLogger()
+		}
+	})
+}
+
+func TestLogErr(t *testing.T) {
+	l, buf := newTestLogger()
+	saveErr := errors.New("disk full")
+
+	if err := l.LogErr(saveErr, "failed to save"); err != saveErr {
+		t.Errorf("LogErr returned %v, want the same error", err)
+	}
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry["level"] != "error" || entry["error"] != "disk full" || entry["message"] != "failed to save" {
+		t.Errorf("entry = %v, want the error and message at error level", entry)
+	}
+	if stack, _ := entry[KeyFileError].(string); !strings.Contains(stack, "TestLogErr") {
+		t.Errorf("stack = %q, want the stack of the caller", stack)
+	}
+
+	buf.Reset()
+	if err := l.LogErr(nil, "nothing"); err != nil {
+		t.Errorf("LogErr(nil) = %v, want nil", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("LogErr(nil) wrote %q, want nothing", buf.String())
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	l, buf := newTestLogger()
+	scoped := l.WithFields(map[string]interface{}{"b": 2, "a": "1", "c": true})
+
+	scoped.Info().Msg("first")
+	scoped.Info().Msg("second")
+	l.Info().Msg("parent")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for _, line := range lines[:2] {
+		if !strings.Contains(line, `"a":"1","b":2,"c":true`) {
+			t.Errorf("line %s, want the fields in key order", line)
+		}
+	}
+	if strings.Contains(lines[2], `"a"`) {
+		t.Errorf("parent line %s, want it without the fields", lines[2])
+	}
+}
+
+func TestFieldPassthroughs(t *testing.T) {
+	l, buf := newTestLogger()
+	child := l.Str("tenant", "acme").Int("shard", 3).Bool("beta", true)
+
+	child.Info().Msg("first")
+	child.Info().Msg("second")
+	l.Info().Msg("parent")
+
+	entries := decodeLines(t, buf)
+	for _, entry := range entries[:2] {
+		if entry["tenant"] != "acme" || entry["shard"] != float64(3) || entry["beta"] != true {
+			t.Errorf("%s: entry %v, want the child fields", entry["message"], entry)
+		}
+	}
+	for _, key := range []string{"tenant", "shard", "beta"} {
+		if _, ok := entries[2][key]; ok {
+			t.Errorf("parent entry %v, want it without %s", entries[2], key)
+		}
+	}
+}
+
+func TestWarnSampledStack(t *testing.T) {
+	l, buf := newTestLogger()
+	key := l.fieldNames().FileError
+
+	sampled := func(rate float64, n int) int {
+		buf.Reset()
+		for i := 0; i < n; i++ {
+			l.WarnSampledStack(rate).Msg("warning")
+		}
+		count := 0
+		for _, entry := range decodeLines(t, buf) {
+			if stack, ok := entry[key].(string); ok {
+				if !strings.Contains(stack, "TestWarnSampledStack") {
+					t.Fatalf("stack %s, want the stack of the caller", stack)
+				}
+				count++
+			}
+		}
+		return count
+	}
+
+	if got := sampled(1, 100); got != 100 {
+		t.Errorf("rate 1: %d stacks of 100, want all", got)
+	}
+	if got := sampled(0, 100); got != 0 {
+		t.Errorf("rate 0: %d stacks of 100, want none", got)
+	}
+	if got := sampled(0.5, 1000); got < 350 || got > 650 {
+		t.Errorf("rate 0.5: %d stacks of 1000, want about 500", got)
+	}
+}
+
+func TestInfoSecure(t *testing.T) {
+	type payment struct {
+		Card   string `encrypt:"true"`
+		Amount int
+	}
+	obj := payment{Card: "4111", Amount: 42}
+
+	t.Run("key set", func(t *testing.T) {
+		setTestKey(t)
+		l, buf := newTestLogger()
+
+		l.InfoSecure("paid", obj)
+
+		entries := decodeLines(t, buf)
+		var got payment
+		if err := json.Unmarshal([]byte(entries[0][KeyPayload].(string)), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Amount != 42 || mustDecrypt(t, got.Card) != "4111" {
+			t.Errorf("payload = %v, want the card encrypted", entries[0][KeyPayload])
+		}
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		prev := keyEncrypt
+		keyEncrypt = nil
+		secureNoKeyOnce = sync.Once{}
+		t.Cleanup(func() { keyEncrypt = prev })
+		l, buf := newTestLogger()
+
+		l.InfoSecure("paid", obj)
+		l.InfoSecure("paid", obj)
+		SetSecureStrict(true)
+		t.Cleanup(func() { SetSecureStrict(false) })
+		l.InfoSecure("strict", obj)
+
+		entries := decodeLines(t, buf)
+		if len(entries) != 4 || entries[0]["level"] != "warn" {
+			t.Fatalf("entries %v, want a single warning before the events", entries)
+		}
+		for _, entry := range entries[1:3] {
+			if entry[KeyPayload] != `{"Card":"4111","Amount":42}` {
+				t.Errorf("payload = %v, want it in plaintext", entry[KeyPayload])
+			}
+		}
+		if _, ok := entries[3][KeyPayload]; ok || entries[3]["message"] != "strict" {
+			t.Errorf("strict entry %v, want it without the payload", entries[3])
+		}
+	})
+}
+
+func TestWithServiceName(t *testing.T) {
+	l, buf := newTestLogger()
+	job := l.WithServiceName("billing")
+
+	job.Info().Msg("job")
+	l.Info().Msg("parent")
+	zl := job.Zerolog()
+	zl.Info().Msg("zerolog")
+	job.Write([]byte(`{"message":"raw"}` + "\n"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, want := range []string{"billing", "test", "billing", "billing"} {
+		if n := strings.Count(lines[i], `"`+KeyServiceName+`"`); n != 1 {
+			t.Errorf("line %s has %d service fields, want 1", lines[i], n)
+		}
+		if !strings.Contains(lines[i], `"`+KeyServiceName+`":"`+want+`"`) {
+			t.Errorf("line %s, want the service name %s", lines[i], want)
+		}
+	}
+}
+
+func TestWithNDJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger("test", WithWriter(buf), WithNDJSON())
+
+	l.Info().Msg("first")
+	l.Warn().Str("multiline", "a\nb").Msg("second")
+	l.Write([]byte("raw  \n\n"))
+
+	out := buf.Bytes()
+	lines := bytes.Split(bytes.TrimSuffix(out, []byte("\n")), []byte("\n"))
+	if len(lines) != 3 || out[len(out)-1] != '\n' {
+		t.Fatalf("output %q, want 3 lines each ending with a newline", out)
+	}
+	for _, line := range lines {
+		if !json.Valid(line) || line[0] != '{' || line[len(line)-1] != '}' {
+			t.Errorf("line %q, want a JSON object without surrounding whitespace", line)
+		}
+	}
+
+	// entries padded by the writer chain are trimmed to a single newline
+	for _, input := range []string{`{"a":1}`, `{"a":1}` + "\n", `{"a":1}` + "  \r\n\n"} {
+		buf.Reset()
+		p := []byte(input)
+		if _, err := (ndjsonWriter{buf}).Write(p); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != `{"a":1}`+"\n" {
+			t.Errorf("Write(%q) wrote %q, want a single trailing newline", input, got)
+		}
+		if string(p) != input {
+			t.Errorf("Write(%q) modified its input to %q", input, p)
+		}
+	}
+}
+
+func TestWithLevelScope(t *testing.T) {
+	l, buf := newTestLogger(WithLogLevel(zerolog.InfoLevel))
+	scoped, end := l.WithLevelScope(zerolog.DebugLevel)
+
+	scoped.Debug().Msg("scoped debug")
+	l.Debug().Msg("parent debug")
+	end()
+	scoped.Debug().Msg("ended debug")
+	scoped.Info().Msg("ended info")
+
+	var messages []string
+	for _, entry := range decodeLines(t, buf) {
+		messages = append(messages, entry["message"].(string))
+	}
+	if want := []string{"scoped debug", "ended info"}; !slices.Equal(messages, want) {
+		t.Errorf("messages %v, want %v", messages, want)
+	}
+	if l.GetLevel() != zerolog.InfoLevel {
+		t.Errorf("parent level %s, want it unchanged", l.GetLevel())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]zerolog.Level{
+		"trace":      zerolog.TraceLevel,
+		"debug":      zerolog.DebugLevel,
+		"Info":       zerolog.InfoLevel,
+		"warn":       zerolog.WarnLevel,
+		"WARNING":    zerolog.WarnLevel,
+		"error":      zerolog.ErrorLevel,
+		"fatal":      zerolog.FatalLevel,
+		"panic":      zerolog.PanicLevel,
+		" disabled ": zerolog.Disabled,
+	}
+	for s, want := range tests {
+		if got, err := ParseLevel(s); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil || !strings.Contains(err.Error(), `"verbose"`) {
+		t.Errorf("ParseLevel(verbose) error = %v, want an error naming the level", err)
+	}
+}
+
+func TestSetLevelFromString(t *testing.T) {
+	global, buf := newTestLogger()
+	setGlobalLogger(t, global)
+
+	if err := SetLevelFromString("warn"); err != nil {
+		t.Fatal(err)
+	}
+	GetLogger().Info().Msg("filtered")
+	GetLogger().Warn().Msg("written")
+	if entries := decodeLines(t, buf); len(entries) != 1 || entries[0]["level"] != "warn" {
+		t.Errorf("entries = %v, want only the warn entry", entries)
+	}
+
+	if err := SetLevelFromString("loud"); err == nil {
+		t.Error("SetLevelFromString(loud) succeeded, want an error")
+	}
+	if GetLogger().GetLevel() != zerolog.WarnLevel {
+		t.Error("an invalid level changed the level of the global logger")
+	}
+}
+
+func TestWithGoroutineID(t *testing.T) {
+	w := make(chanWriter, 2)
+	l := NewLogger("test", WithWriter(w), WithGoroutineID(true))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Info().Msg("from a goroutine")
+		}()
+	}
+	wg.Wait()
+	close(w)
+
+	var gids []float64
+	for line := range w {
+		var entry struct{ GID float64 }
+		if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.GID == 0 {
+			t.Fatalf("line %s, want a gid field", line)
+		}
+		gids = append(gids, entry.GID)
+	}
+	if gids[0] == gids[1] {
+		t.Errorf("gids = %v, want a distinct one per goroutine", gids)
+	}
+
+	l, buf := newTestLogger()
+	l.Info().Msg("off by default")
+	if _, ok := decodeLines(t, buf)[0][KeyGoroutineID]; ok {
+		t.Error("entry carries a gid without WithGoroutineID")
+	}
+}
+
+func TestFormatShortcuts(t *testing.T) {
+	l, buf := newTestLogger(WithLogLevel(zerolog.InfoLevel))
+
+	l.Debugf("below the level %d", 1)
+	l.Infof("processed %d items", 3)
+	l.Warnf("slow %s", "query")
+	l.Errorf("failed %v", errors.New("boom"))
+
+	entries := decodeLines(t, buf)
+	want := []struct{ level, message string }{
+		{"info", "processed 3 items"},
+		{"warn", "slow query"},
+		{"error", "failed boom"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		if entries[i]["level"] != w.level || entries[i]["message"] != w.message {
+			t.Errorf("entry %d = %v, want %s %q", i, entries[i], w.level, w.message)
+		}
+	}
+}
+
+func TestFormatCtxShortcuts(t *testing.T) {
+	l, buf := newTestLogger()
+	ctx := ContextWithTraceInfo(context.Background(), TraceInfo{RequestID: "rid-1"})
+
+	l.InfofCtx(ctx, "n=%d", 1)
+	l.WarnfCtx(ctx, "n=%d", 2)
+	l.ErrorfCtx(context.Background(), "no trace")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for _, entry := range entries[:2] {
+		trace, _ := entry[KeyTraceInfo].(map[string]interface{})
+		if trace["request_id"] != "rid-1" {
+			t.Errorf("entry = %v, want the request ID of the context", entry)
+		}
+	}
+	for _, entry := range entries {
+		if caller, _ := entry["caller"].(string); !strings.Contains(caller, "logger_test.go") || !strings.Contains(caller, "TestFormatCtxShortcuts") {
+			t.Errorf("caller = %q, want the line calling the shortcut", caller)
+		}
+	}
+	if _, ok := entries[2][KeyTraceInfo]; ok {
+		t.Errorf("entry = %v, want no trace info without one in the context", entries[2])
+	}
+}
@@ -0,0 +1,118 @@
+// Package loggrpc provides gRPC server interceptors logging the RPCs with the go-logging logger.
+// It is a separate package so that only the services importing it depend on gRPC.
+package loggrpc
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/gotech-hub/go-logging"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	KeyDuration   = "duration"
+	KeyStatusCode = "status_code"
+)
+
+// UnaryServerInterceptor returns an interceptor logging each unary RPC with its method, duration,
+// status code and the request and response messages encrypted by the tag walker, left out when
+// no key is set, as the bodies of the Echo and Fiber middlewares.
+// The handler's context carries the request ID and a logger scoped to it, retrieved with logger.Ctx.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx, l := scopeContext(ctx)
+
+		resp, err := handler(ctx, req)
+
+		names := logger.GetFieldNames()
+		e := l.Info()
+		if err != nil {
+			e = l.Error().Err(err)
+		}
+		e = e.Str(logger.KeyMethod, info.FullMethod).
+			Dur(KeyDuration, time.Since(start)).
+			Str(KeyStatusCode, status.Code(err).String())
+		if msg, ok := encryptMessage(req); ok {
+			e = e.Interface(names.RequestBody, msg)
+		}
+		if err == nil {
+			if msg, ok := encryptMessage(resp); ok {
+				e = e.Interface(names.ResponseBody, msg)
+			}
+		}
+		e.Msg("grpc unary call")
+
+		return resp, err
+	}
+}
+
+// scopeContext stores the request ID of the call in ctx, taking it from the trace info or the
+// incoming metadata, as by TraceInfoFromMetadata, or generating one, along with a logger carrying it.
+func scopeContext(ctx context.Context) (context.Context, *logger.Logger) {
+	traceInfo := logger.GetRequestIdByContext(ctx)
+	if traceInfo == nil || traceInfo.RequestID == "" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		fromMetadata := TraceInfoFromMetadata(md)
+		if fromMetadata.RequestID == "" {
+			fromMetadata.RequestID = logger.NewRequestID()
+		}
+		ctx = logger.MergeTraceInfo(ctx, fromMetadata)
+		traceInfo = logger.GetRequestIdByContext(ctx)
+	}
+
+	base := logger.GetLogger()
+	if base == nil {
+		lg := logger.FromZerolog(log.Logger)
+		base = &lg
+	}
+
+	c := base.With().RequestID(traceInfo.RequestID)
+	if traceInfo.TraceID != "" {
+		c = c.Str(logger.KeyTraceID, traceInfo.TraceID)
+	}
+	l := c.Logger()
+	return l.WithContext(ctx), &l
+}
+
+// TraceInfoFromMetadata returns the trace information sent in md, read from the keys set by
+// logger.SetTraceHeaderNames. The fields without a value in md are left empty.
+func TraceInfoFromMetadata(md metadata.MD) logger.TraceInfo {
+	names := logger.GetTraceHeaderNames()
+	return logger.TraceInfo{
+		RequestID: firstMetadataValue(md, names.RequestID),
+		TraceID:   firstMetadataValue(md, names.TraceID),
+	}
+}
+
+// firstMetadataValue returns the first non-empty value in md of the keys, tried in order.
+func firstMetadataValue(md metadata.MD, keys []string) string {
+	for _, key := range keys {
+		// Get lowercases key, as the metadata keys are
+		for _, value := range md.Get(key) {
+			if value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// encryptMessage returns a copy of msg with its tagged fields encrypted.
+// It reports false when msg is nil or can't be encrypted, including when no key is set,
+// so that no plaintext is logged.
+func encryptMessage(msg interface{}) (interface{}, bool) {
+	if msg == nil || !logger.HasKeyEncrypt() {
+		return nil, false
+	}
+
+	encrypted, err := logger.EncryptInterface(msg)
+	if err != nil {
+		return nil, false
+	}
+	return encrypted, true
+}
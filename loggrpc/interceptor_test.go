@@ -0,0 +1,169 @@
+package loggrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	logger "github.com/gotech-hub/go-logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// buf receives the entries of the global logger.
+var buf bytes.Buffer
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test", logger.WithWriter(&buf))
+	logger.SetKeyEncrypt("000102030405060708090a0b0c0d0e0f")
+	os.Exit(m.Run())
+}
+
+// decodeLines decodes the JSON entries written to buf since the last call.
+func decodeLines(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	defer buf.Reset()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid entry %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+type secretMessage struct {
+	Card string `encrypt:"true"`
+	Name string
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	buf.Reset()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "rid-1"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc.Payments/Pay"}
+
+	var requestID string
+	resp, err := UnaryServerInterceptor()(ctx, &secretMessage{Card: "4111", Name: "req"}, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			requestID = logger.GetRequestIdByContext(ctx).RequestID
+			logger.Ctx(ctx).Info().Msg("handling")
+			return &secretMessage{Card: "5500", Name: "resp"}, nil
+		})
+	if err != nil || resp.(*secretMessage).Card != "5500" {
+		t.Fatalf("got %v, %v, want the handler's response unchanged", resp, err)
+	}
+	if requestID != "rid-1" {
+		t.Errorf("request ID in the handler's context = %q, want rid-1", requestID)
+	}
+
+	entries := decodeLines(t)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0][logger.KeyRequestID] != "rid-1" {
+		t.Errorf("handler entry = %v, want the scoped request ID", entries[0])
+	}
+	call := entries[1]
+	if call[logger.KeyMethod] != info.FullMethod || call[KeyStatusCode] != "OK" || call[logger.KeyRequestID] != "rid-1" {
+		t.Errorf("call entry = %v, want the method, status code and request ID", call)
+	}
+	if _, ok := call[KeyDuration]; !ok {
+		t.Errorf("call entry = %v, want the duration", call)
+	}
+	reqBody, _ := call[logger.KeyRequestBody].(map[string]interface{})
+	respBody, _ := call[logger.KeyResponseBody].(map[string]interface{})
+	if reqBody["Name"] != "req" || !logger.IsEncrypted(reqBody["Card"].(string)) {
+		t.Errorf("request = %v, want its card encrypted", reqBody)
+	}
+	if respBody["Name"] != "resp" || !logger.IsEncrypted(respBody["Card"].(string)) {
+		t.Errorf("response = %v, want its card encrypted", respBody)
+	}
+}
+
+func TestUnaryServerInterceptorError(t *testing.T) {
+	buf.Reset()
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc.Payments/Refund"}
+	handlerErr := errors.New("refused")
+
+	_, err := UnaryServerInterceptor()(context.Background(), &secretMessage{}, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		})
+	if err != handlerErr {
+		t.Fatalf("err = %v, want the handler's error", err)
+	}
+
+	entries := decodeLines(t)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	call := entries[0]
+	if call["level"] != "error" || call["error"] != "refused" || call[KeyStatusCode] != "Unknown" {
+		t.Errorf("call entry = %v, want the error and its status code", call)
+	}
+	if id, _ := call[logger.KeyRequestID].(string); id == "" {
+		t.Errorf("call entry = %v, want a generated request ID", call)
+	}
+	if _, ok := call[logger.KeyResponseBody]; ok {
+		t.Errorf("call entry = %v, want no response", call)
+	}
+}
+
+func TestTraceInfoFromMetadata(t *testing.T) {
+	t.Cleanup(func() { logger.SetTraceHeaderNames(logger.TraceHeaderNames{}) })
+
+	tests := []struct {
+		name  string
+		names logger.TraceHeaderNames
+		md    metadata.MD
+		want  logger.TraceInfo
+	}{
+		{"defaults", logger.TraceHeaderNames{}, metadata.Pairs("x-request-id", "rid-1", "x-trace-id", "trace-1"),
+			logger.TraceInfo{RequestID: "rid-1", TraceID: "trace-1"}},
+		{"fallback", logger.TraceHeaderNames{}, metadata.Pairs("x-request-id", "", "x-correlation-id", "corr-1"),
+			logger.TraceInfo{RequestID: "corr-1"}},
+		{"configured", logger.TraceHeaderNames{RequestID: []string{"X-Amzn-Trace-Id"}, TraceID: []string{"traceparent"}},
+			metadata.Pairs("x-request-id", "rid-1", "x-amzn-trace-id", "amzn-1", "traceparent", "00-abc"),
+			logger.TraceInfo{RequestID: "amzn-1", TraceID: "00-abc"}},
+		{"absent", logger.TraceHeaderNames{}, metadata.MD{}, logger.TraceInfo{}},
+		{"nil", logger.TraceHeaderNames{}, nil, logger.TraceInfo{}},
+	}
+	for _, tt := range tests {
+		logger.SetTraceHeaderNames(tt.names)
+		if got := TraceInfoFromMetadata(tt.md); got.RequestID != tt.want.RequestID || got.TraceID != tt.want.TraceID {
+			t.Errorf("%s: TraceInfoFromMetadata = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnaryServerInterceptorTraceID(t *testing.T) {
+	buf.Reset()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-trace-id", "trace-1"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc.Payments/Pay"}
+
+	var traceInfo *logger.TraceInfo
+	_, err := UnaryServerInterceptor()(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		traceInfo = logger.GetRequestIdByContext(ctx)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if traceInfo == nil || traceInfo.TraceID != "trace-1" || traceInfo.RequestID == "" {
+		t.Fatalf("trace info in the handler's context = %+v, want the trace ID and a generated request ID", traceInfo)
+	}
+	if call := decodeLines(t)[0]; call[logger.KeyTraceID] != "trace-1" || call[logger.KeyRequestID] != traceInfo.RequestID {
+		t.Errorf("call entry = %v, want the trace and request IDs", call)
+	}
+}
@@ -0,0 +1,127 @@
+package loggrpc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/gotech-hub/go-logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	KeyMessagesReceived = "messages_received"
+	KeyMessagesSent     = "messages_sent"
+)
+
+// StreamOption configures the interceptor built by StreamServerInterceptor.
+type StreamOption func(*streamOptions)
+
+type streamOptions struct {
+	samples bool
+}
+
+// WithMessageSamples logs the first message received and the first message sent on the stream,
+// encrypted by the tag walker, along with the end of the stream. No message is sampled when no key
+// is set, so that none is logged in plaintext.
+func WithMessageSamples() StreamOption {
+	return func(o *streamOptions) {
+		o.samples = true
+	}
+}
+
+// StreamServerInterceptor returns an interceptor logging the start and the end of each streaming RPC,
+// with its duration, status code and the number of messages received and sent.
+// The stream's context carries the request ID and a logger scoped to it, as for UnaryServerInterceptor.
+func StreamServerInterceptor(opts ...StreamOption) grpc.StreamServerInterceptor {
+	o := &streamOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, l := scopeContext(ss.Context())
+
+		l.Info().
+			Str(logger.KeyMethod, info.FullMethod).
+			Msg("grpc stream started")
+
+		stream := &loggedStream{ServerStream: ss, ctx: ctx, samples: o.samples && logger.HasKeyEncrypt()}
+		err := handler(srv, stream)
+
+		names := logger.GetFieldNames()
+		e := l.Info()
+		if err != nil {
+			e = l.Error().Err(err)
+		}
+		e = e.Str(logger.KeyMethod, info.FullMethod).
+			Dur(KeyDuration, time.Since(start)).
+			Str(KeyStatusCode, status.Code(err).String()).
+			Int64(KeyMessagesReceived, stream.received.Load()).
+			Int64(KeyMessagesSent, stream.sent.Load())
+		if stream.firstReceived != nil {
+			e = e.Interface(names.RequestBody, stream.firstReceived)
+		}
+		if stream.firstSent != nil {
+			e = e.Interface(names.ResponseBody, stream.firstSent)
+		}
+		e.Msg("grpc stream finished")
+
+		return err
+	}
+}
+
+// loggedStream wraps a grpc.ServerStream to count its messages and return the scoped context.
+// SendMsg and RecvMsg may be called from different goroutines.
+type loggedStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	samples bool
+
+	received, sent         atomic.Int64
+	receivedOnce, sentOnce sync.Once
+	firstReceived          interface{}
+	firstSent              interface{}
+}
+
+// Context returns the context carrying the request ID and the scoped logger.
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg receives a message from the client and counts it.
+func (s *loggedStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+
+	s.received.Add(1)
+	if s.samples {
+		// m is encrypted right away as the handler may reuse it for the next messages
+		s.receivedOnce.Do(func() {
+			s.firstReceived, _ = encryptMessage(m)
+		})
+	}
+	return nil
+}
+
+// SendMsg sends a message to the client and counts it.
+func (s *loggedStream) SendMsg(m interface{}) error {
+	if s.samples {
+		s.sentOnce.Do(func() {
+			s.firstSent, _ = encryptMessage(m)
+		})
+	}
+
+	err := s.ServerStream.SendMsg(m)
+	if err != nil {
+		return err
+	}
+
+	s.sent.Add(1)
+	return nil
+}
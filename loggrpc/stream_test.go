@@ -0,0 +1,104 @@
+package loggrpc
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	logger "github.com/gotech-hub/go-logging"
+	"google.golang.org/grpc"
+)
+
+// fakeStream is a grpc.ServerStream receiving the messages of in and recording those sent.
+type fakeStream struct {
+	grpc.ServerStream
+	in   []secretMessage
+	sent []interface{}
+}
+
+func (s *fakeStream) Context() context.Context { return context.Background() }
+
+func (s *fakeStream) RecvMsg(m interface{}) error {
+	if len(s.in) == 0 {
+		return io.EOF
+	}
+	*m.(*secretMessage) = s.in[0]
+	s.in = s.in[1:]
+	return nil
+}
+
+func (s *fakeStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+// echoHandler sends back each message received, reusing the same message for all of them.
+func echoHandler(t *testing.T) grpc.StreamHandler {
+	return func(srv interface{}, ss grpc.ServerStream) error {
+		if info := logger.GetRequestIdByContext(ss.Context()); info == nil || info.RequestID == "" {
+			t.Error("no request ID in the stream's context")
+		}
+		var m secretMessage
+		for ss.RecvMsg(&m) == nil {
+			if err := ss.SendMsg(&secretMessage{Card: "9" + m.Card, Name: m.Name}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	buf.Reset()
+	ss := &fakeStream{in: []secretMessage{{Card: "4111", Name: "a"}, {Card: "4222", Name: "b"}}}
+	info := &grpc.StreamServerInfo{FullMethod: "/svc.Payments/Stream"}
+
+	if err := StreamServerInterceptor()(nil, ss, info, echoHandler(t)); err != nil {
+		t.Fatal(err)
+	}
+	if len(ss.sent) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(ss.sent))
+	}
+
+	entries := decodeLines(t)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	start, end := entries[0], entries[1]
+	if start["message"] != "grpc stream started" || start[logger.KeyMethod] != info.FullMethod {
+		t.Errorf("start entry = %v", start)
+	}
+	if end["message"] != "grpc stream finished" || end[KeyStatusCode] != "OK" ||
+		end[KeyMessagesReceived] != float64(2) || end[KeyMessagesSent] != float64(2) {
+		t.Errorf("end entry = %v, want the status code and message counts", end)
+	}
+	if start[logger.KeyRequestID] == nil || start[logger.KeyRequestID] != end[logger.KeyRequestID] {
+		t.Errorf("request IDs = %v and %v, want the same one on both entries", start[logger.KeyRequestID], end[logger.KeyRequestID])
+	}
+	if _, ok := end[logger.KeyRequestBody]; ok {
+		t.Errorf("end entry = %v, want no sampled message without WithMessageSamples", end)
+	}
+}
+
+func TestStreamServerInterceptorMessageSamples(t *testing.T) {
+	buf.Reset()
+	ss := &fakeStream{in: []secretMessage{{Card: "4111", Name: "a"}, {Card: "4222", Name: "b"}}}
+	info := &grpc.StreamServerInfo{FullMethod: "/svc.Payments/Stream"}
+
+	if err := StreamServerInterceptor(WithMessageSamples())(nil, ss, info, echoHandler(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	entries := decodeLines(t)
+	if strings.Contains(out, "4111") {
+		t.Errorf("output = %s, want no plaintext card", out)
+	}
+	end := entries[len(entries)-1]
+	received, _ := end[logger.KeyRequestBody].(map[string]interface{})
+	sent, _ := end[logger.KeyResponseBody].(map[string]interface{})
+	if received["Name"] != "a" || sent["Name"] != "a" {
+		t.Errorf("samples = %v and %v, want the first message received and sent", received, sent)
+	}
+}
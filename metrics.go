@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+var metricsObserver atomic.Pointer[func(level zerolog.Level)]
+
+// SetMetricsObserver sets a function called with the level of every event written by the loggers
+// built by InitLog and NewLogger, e.g. to increment counters. A nil observer removes it.
+func SetMetricsObserver(observer func(level zerolog.Level)) {
+	if observer == nil {
+		metricsObserver.Store(nil)
+		return
+	}
+	metricsObserver.Store(&observer)
+}
+
+// metricsHook reports the level of each event to the metrics observer.
+type metricsHook struct{}
+
+// Run implements zerolog.Hook.
+func (metricsHook) Run(_ *zerolog.Event, level zerolog.Level, _ string) {
+	if observer := metricsObserver.Load(); observer != nil {
+		(*observer)(level)
+	}
+}
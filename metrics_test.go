@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSetMetricsObserver(t *testing.T) {
+	counts := map[zerolog.Level]int{}
+	SetMetricsObserver(func(level zerolog.Level) { counts[level]++ })
+	t.Cleanup(func() { SetMetricsObserver(nil) })
+	l, _ := newTestLogger(WithLogLevel(zerolog.InfoLevel))
+
+	l.Info().Msg("1")
+	l.Info().Msg("2")
+	l.Warn().Msg("3")
+	l.Error().Msg("4")
+	l.Debug().Msg("below the level, not written")
+	child := l.With().RequestID("r1").Logger()
+	child.Info().Msg("5")
+
+	want := map[zerolog.Level]int{zerolog.InfoLevel: 3, zerolog.WarnLevel: 1, zerolog.ErrorLevel: 1}
+	if len(counts) != len(want) {
+		t.Fatalf("counts = %v, want %v", counts, want)
+	}
+	for level, n := range want {
+		if counts[level] != n {
+			t.Errorf("counts = %v, want %v", counts, want)
+			break
+		}
+	}
+
+	SetMetricsObserver(nil)
+	l.Info().Msg("not observed")
+	if counts[zerolog.InfoLevel] != 3 {
+		t.Errorf("info count = %d after removing the observer, want 3", counts[zerolog.InfoLevel])
+	}
+}
@@ -0,0 +1,227 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// Option configures a Logger built by NewLogger or InitLog.
+type Option func(*options)
+
+// FieldNames holds the keys of the fields added by this package.
+// Empty names keep their default.
+type FieldNames struct {
+	Service      string // defaults to KeyServiceName
+	FileError    string // defaults to KeyFileError
+	RequestBody  string // defaults to KeyRequestBody
+	ResponseBody string // defaults to KeyResponseBody
+}
+
+func defaultFieldNames() FieldNames {
+	return FieldNames{
+		Service:      KeyServiceName,
+		FileError:    KeyFileError,
+		RequestBody:  KeyRequestBody,
+		ResponseBody: KeyResponseBody,
+	}
+}
+
+type options struct {
+	names       *FieldNames
+	writer      io.Writer
+	level       *zerolog.Level
+	asyncBuffer int
+	asyncPolicy OverflowPolicy
+	console     bool
+	fieldOrder  []string
+	autoStack   bool
+	goroutineID bool
+	ndjson      bool
+}
+
+// WithWriter sets the writer the logger outputs to.
+func WithWriter(w io.Writer) Option {
+	return func(o *options) {
+		o.writer = w
+	}
+}
+
+// WithLogLevel sets the minimum level of the logger.
+func WithLogLevel(lvl zerolog.Level) Option {
+	return func(o *options) {
+		o.level = &lvl
+	}
+}
+
+// WithAsyncWriter makes the logger write asynchronously through an AsyncWriter
+// buffering up to buffer entries in front of the configured writer.
+func WithAsyncWriter(buffer int) Option {
+	return func(o *options) {
+		o.asyncBuffer = buffer
+	}
+}
+
+// WithOverflowPolicy sets what the AsyncWriter does when its buffer is full.
+// It defaults to OverflowBlock.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(o *options) {
+		o.asyncPolicy = policy
+	}
+}
+
+// WithConsoleFieldOrder makes the logger write human-readable lines with a zerolog.ConsoleWriter,
+// rendering the fields of order first, in that order, followed by the other fields.
+// An empty order defaults to the time, level, service name, request ID and message.
+func WithConsoleFieldOrder(order []string) Option {
+	return func(o *options) {
+		o.console = true
+		o.fieldOrder = order
+	}
+}
+
+// WithAutoStackOnError adds the stack of the caller under the FileError field to the events
+// at Error level and above, without calling StackTrace.
+func WithAutoStackOnError(enabled bool) Option {
+	return func(o *options) {
+		o.autoStack = enabled
+	}
+}
+
+// WithGoroutineID adds the ID of the goroutine sending each event under KeyGoroutineID.
+// Reading it costs a stack capture per event, so it is meant for debugging and off by default.
+func WithGoroutineID(enabled bool) Option {
+	return func(o *options) {
+		o.goroutineID = enabled
+	}
+}
+
+// WithNDJSON makes each entry written end with exactly one newline, trimming the trailing whitespace
+// a custom writer chain may add, for the collectors reading one JSON object per line.
+func WithNDJSON() Option {
+	return func(o *options) {
+		o.ndjson = true
+	}
+}
+
+// WithFieldNames sets the keys of the fields added by this package.
+func WithFieldNames(names FieldNames) Option {
+	return func(o *options) {
+		defaults := defaultFieldNames()
+		if names.Service == "" {
+			names.Service = defaults.Service
+		}
+		if names.FileError == "" {
+			names.FileError = defaults.FileError
+		}
+		if names.RequestBody == "" {
+			names.RequestBody = defaults.RequestBody
+		}
+		if names.ResponseBody == "" {
+			names.ResponseBody = defaults.ResponseBody
+		}
+		o.names = &names
+	}
+}
+
+// newOptions applies opts over the default options.
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// hasOutput reports whether the options change the writer of the logger.
+func (o *options) hasOutput() bool {
+	return o.writer != nil || o.asyncBuffer > 0 || o.console || o.ndjson
+}
+
+// output returns the writer configured by the options, defaulting to w.
+// names are the field names of the logger, used by the console field order.
+func (o *options) output(w io.Writer, names FieldNames) io.Writer {
+	if o.writer != nil {
+		w = o.writer
+	}
+	if o.console {
+		w = newConsoleWriter(w, o.fieldOrder, names)
+	}
+	if o.ndjson {
+		w = ndjsonWriter{w}
+	}
+	if o.asyncBuffer > 0 {
+		// the console formatting runs in the background goroutine
+		w = NewAsyncWriter(w, o.asyncBuffer, o.asyncPolicy)
+	}
+	return w
+}
+
+// newConsoleWriter returns a ConsoleWriter to out rendering the fields of order first.
+func newConsoleWriter(out io.Writer, order []string, names FieldNames) zerolog.ConsoleWriter {
+	if len(order) == 0 {
+		order = []string{
+			zerolog.TimestampFieldName,
+			zerolog.LevelFieldName,
+			names.Service,
+			KeyRequestID,
+			zerolog.MessageFieldName,
+		}
+	}
+
+	cw := zerolog.ConsoleWriter{Out: out, PartsOrder: order}
+	// the fields rendered as parts are excluded from the trailing fields
+	for _, name := range order {
+		switch name {
+		case zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.CallerFieldName:
+		default:
+			cw.FieldsExclude = append(cw.FieldsExclude, name)
+		}
+	}
+	cw.FormatPartValueByName = func(value interface{}, name string) string {
+		if value == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s=%v", name, value)
+	}
+	return cw
+}
+
+// ndjsonWriter writes each entry with its trailing whitespace replaced by a single newline.
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+// Write writes p trimmed of its trailing whitespace, followed by a newline.
+func (n ndjsonWriter) Write(p []byte) (int, error) {
+	line := bytes.TrimRight(p, " \t\r\n")
+	// p must not be modified, a line to fix is copied
+	if len(line) != len(p)-1 || p[len(line)] != '\n' {
+		line = append(bytes.Clone(line), '\n')
+	} else {
+		line = p
+	}
+	if _, err := n.w.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// apply returns a copy of lg configured with the options.
+// names are the field names of the logger, used by the stack added on error.
+func (o *options) apply(lg zerolog.Logger, names FieldNames) zerolog.Logger {
+	if o.level != nil {
+		lg = lg.Level(*o.level)
+	}
+	if o.autoStack {
+		lg = lg.Hook(stackHook{key: names.FileError})
+	}
+	if o.goroutineID {
+		lg = lg.Hook(goroutineHook{})
+	}
+	return lg.Hook(metricsHook{})
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// KeyPanic is the key of the recovered panic value.
+const KeyPanic = "panic"
+
+var repanicOnRecover atomic.Bool
+
+// SetRecoverRepanic sets whether Recover panics again after logging the recovered value.
+func SetRecoverRepanic(repanic bool) {
+	repanicOnRecover.Store(repanic)
+}
+
+// Recover recovers from a panic and logs it at Error level with a stack trace.
+// It must be deferred at the top of a goroutine: defer l.Recover().
+func (l *Logger) Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	l.Error().
+		Interface(KeyPanic, r).
+		Str(l.fieldNames().FileError, panicFrame()).
+		Msg("recovered from panic")
+
+	if repanicOnRecover.Load() {
+		panic(r)
+	}
+}
+
+// SafeGo runs fn in a goroutine recovering and logging its panics with the global logger.
+func SafeGo(fn func()) {
+	l := globalLogger()
+
+	go func() {
+		defer l.Recover()
+		fn()
+	}()
+}
+
+// panicFrame returns the frame that panicked, as GetFullStack formats it, for Recover to call.
+// The stack of a deferred call runs through the runtime panic frames, skipped to reach the frame
+// that called panic or faulted.
+func panicFrame() string {
+	pcs := make([]uintptr, 64)
+	// skip runtime.Callers, panicFrame and Recover
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	inRuntime := false
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "runtime.") {
+			inRuntime = true
+		} else if inRuntime {
+			file := trimCallerFile(frame.File, frame.Function)
+			return fmt.Sprintf("file: %s:%d, func: %s", file, frame.Line, frame.Function)
+		}
+		if !more {
+			return GetFullStack()
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// chanWriter sends each entry written to it on a channel.
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func faultingFunc() {
+	var m map[string]int
+	m["x"] = 1
+}
+
+func TestRecover(t *testing.T) {
+	l, buf := newTestLogger()
+
+	func() {
+		defer l.Recover()
+		faultingFunc()
+	}()
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry["level"] != "error" || !strings.Contains(entry[KeyPanic].(string), "nil map") {
+		t.Errorf("entry = %v, want the panic value at error level", entry)
+	}
+	if stack, _ := entry[KeyFileError].(string); !strings.Contains(stack, "faultingFunc") {
+		t.Errorf("stack = %q, want it to start at the panicking function", stack)
+	}
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	SetRecoverRepanic(true)
+	t.Cleanup(func() { SetRecoverRepanic(false) })
+	l, buf := newTestLogger()
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recovered %v, want the panic raised again", r)
+		}
+		if len(decodeLines(t, buf)) != 1 {
+			t.Error("the panic wasn't logged before panicking again")
+		}
+	}()
+	defer l.Recover()
+	panic("boom")
+}
+
+func TestSafeGo(t *testing.T) {
+	w := make(chanWriter, 1)
+	setGlobalLogger(t, NewLogger("test", WithWriter(w)))
+
+	SafeGo(func() { panic("background") })
+
+	select {
+	case entry := <-w:
+		if !strings.Contains(entry, `"panic":"background"`) {
+			t.Errorf("entry = %s, want the recovered value", entry)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the panic of the goroutine wasn't logged")
+	}
+}
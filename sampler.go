@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// EveryNSampler returns a sampler letting one event out of every n through.
+func EveryNSampler(n uint32) zerolog.Sampler {
+	return &zerolog.BasicSampler{N: n}
+}
+
+// BurstSampler returns a sampler letting up to burst events through per period,
+// dropping the others.
+func BurstSampler(burst uint32, period time.Duration) zerolog.Sampler {
+	return &zerolog.BurstSampler{Burst: burst, Period: period}
+}
+
+// LevelSampler returns a sampler applying s to Trace and Debug events only,
+// so Info and above always go through unsampled.
+func LevelSampler(s zerolog.Sampler) zerolog.Sampler {
+	return &zerolog.LevelSampler{TraceSampler: s, DebugSampler: s}
+}
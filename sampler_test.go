@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestEveryNSampler(t *testing.T) {
+	l, buf := newTestLogger(WithLogLevel(zerolog.DebugLevel))
+	sampled := l.Sample(EveryNSampler(100))
+
+	for i := 0; i < 1000; i++ {
+		sampled.Debug().Int("i", i).Msg("debug")
+	}
+
+	if got := len(decodeLines(t, buf)); got < 9 || got > 11 {
+		t.Errorf("got %d entries, want about 10", got)
+	}
+}
+
+func TestBurstSampler(t *testing.T) {
+	l, buf := newTestLogger()
+	sampled := l.Sample(BurstSampler(5, time.Hour))
+
+	for i := 0; i < 50; i++ {
+		sampled.Info().Msg("burst")
+	}
+
+	if got := len(decodeLines(t, buf)); got != 5 {
+		t.Errorf("got %d entries, want the burst of 5", got)
+	}
+}
+
+func TestLevelSampler(t *testing.T) {
+	l, buf := newTestLogger(WithLogLevel(zerolog.DebugLevel))
+	sampled := l.Sample(LevelSampler(EveryNSampler(100)))
+
+	for i := 0; i < 100; i++ {
+		sampled.Debug().Msg("debug")
+		sampled.Warn().Msg("warn")
+	}
+
+	levels := map[string]int{}
+	for _, entry := range decodeLines(t, buf) {
+		levels[entry["level"].(string)]++
+	}
+	if levels["debug"] != 1 || levels["warn"] != 100 {
+		t.Errorf("entries per level = %v, want 1 debug and 100 warn", levels)
+	}
+}
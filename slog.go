@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// SlogHandler returns a slog.Handler writing the records through the logger, e.g. with slog.New,
+// the attributes being added as fields and the groups qualifying their keys with dots.
+// The attributes whose key, or group qualified key, is one of encryptKeys are encrypted: a struct has
+// its tagged fields encrypted as by EncInterface, and any other value, a time in RFC 3339, is encrypted
+// as a string by EncStr.
+func (l *Logger) SlogHandler(encryptKeys ...string) slog.Handler {
+	encrypt := make(map[string]bool, len(encryptKeys))
+	for _, key := range encryptKeys {
+		encrypt[key] = true
+	}
+	return &slogHandler{l: *l, encrypt: encrypt}
+}
+
+// slogHandler implements slog.Handler over a Logger.
+type slogHandler struct {
+	l       Logger
+	encrypt map[string]bool
+	prefix  string     // keys prefix of the groups opened by WithGroup
+	attrs   []slogAttr // attributes added by WithAttrs
+}
+
+// slogAttr is an attribute added by WithAttrs along with the prefix of its groups.
+type slogAttr struct {
+	prefix string
+	attr   slog.Attr
+}
+
+// slogLevel maps a slog level to the zerolog level, the levels below Debug being Trace.
+func slogLevel(level slog.Level) zerolog.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return zerolog.TraceLevel
+	case level < slog.LevelInfo:
+		return zerolog.DebugLevel
+	case level < slog.LevelWarn:
+		return zerolog.InfoLevel
+	case level < slog.LevelError:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	lvl := slogLevel(level)
+	return lvl >= h.l.GetLevel() && lvl >= zerolog.GlobalLevel()
+}
+
+// Handle implements slog.Handler.
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	e := h.l.WithLevel(slogLevel(r.Level)).Ctx(ctx)
+	for _, a := range h.attrs {
+		h.addAttr(e, a.prefix, a.attr)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		h.addAttr(e, h.prefix, a)
+		return true
+	})
+	e.Msg(r.Message)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = make([]slogAttr, 0, len(h.attrs)+len(attrs))
+	h2.attrs = append(h2.attrs, h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, slogAttr{prefix: h.prefix, attr: a})
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// addAttr adds a to e under its key qualified by prefix, recursing into groups.
+func (h *slogHandler) addAttr(e *Event, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		// a group without key is inlined
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			h.addAttr(e, prefix, ga)
+		}
+		return
+	}
+
+	key := prefix + a.Key
+	if h.encrypt[a.Key] || h.encrypt[key] {
+		// the tag walker leaves the scalars and times as is, only a struct has its tagged fields encrypted
+		switch v := reflect.Indirect(reflect.ValueOf(a.Value.Any())); {
+		case a.Value.Kind() == slog.KindTime:
+			e.EncStr(key, a.Value.Time().Format(time.RFC3339Nano))
+		case a.Value.Kind() == slog.KindAny && v.Kind() == reflect.Struct && !isOpaqueType(v.Type()):
+			e.EncInterface(key, a.Value.Any())
+		default:
+			e.EncStr(key, a.Value.String())
+		}
+		return
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		e.Str(key, a.Value.String())
+	case slog.KindInt64:
+		e.Int64(key, a.Value.Int64())
+	case slog.KindUint64:
+		e.Uint64(key, a.Value.Uint64())
+	case slog.KindFloat64:
+		e.Float64(key, a.Value.Float64())
+	case slog.KindBool:
+		e.Bool(key, a.Value.Bool())
+	case slog.KindDuration:
+		e.Dur(key, a.Value.Duration())
+	case slog.KindTime:
+		e.Time(key, a.Value.Time())
+	default:
+		if err, ok := a.Value.Any().(error); ok {
+			e.AnErr(key, err)
+			return
+		}
+		e.Interface(key, a.Value.Any())
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSlogHandler(t *testing.T) {
+	l, buf := newTestLogger(WithLogLevel(zerolog.DebugLevel))
+	sl := slog.New(l.SlogHandler())
+
+	sl.With("user", "bob").WithGroup("req").Info("hello",
+		"n", 3, "ok", true, "d", time.Second, slog.Group("sub", "x", "y"), "err", errors.New("e"))
+	sl.Debug("debug")
+	sl.Warn("warn")
+	sl.Error("error")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(entries))
+	}
+	hello := entries[0]
+	for key, want := range map[string]interface{}{
+		"message": "hello", "level": "info", "user": "bob",
+		"req.n": float64(3), "req.ok": true, "req.sub.x": "y", "req.err": "e",
+	} {
+		if hello[key] != want {
+			t.Errorf("%s = %v, want %v", key, hello[key], want)
+		}
+	}
+	for i, level := range []string{"debug", "warn", "error"} {
+		if entries[i+1]["level"] != level {
+			t.Errorf("entry %d level = %v, want %s", i+1, entries[i+1]["level"], level)
+		}
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	l, buf := newTestLogger(WithLogLevel(zerolog.WarnLevel))
+	sl := slog.New(l.SlogHandler())
+
+	sl.Info("filtered")
+	sl.Warn("written")
+
+	if entries := decodeLines(t, buf); len(entries) != 1 || entries[0]["message"] != "written" {
+		t.Errorf("entries = %v, want only the warn entry", entries)
+	}
+}
+
+func TestSlogHandlerEncryptKeys(t *testing.T) {
+	setTestKey(t)
+	l, buf := newTestLogger()
+	sl := slog.New(l.SlogHandler("card", "req.pan", "account"))
+
+	type account struct {
+		IBAN string `encrypt:"true"`
+		Name string
+	}
+	sl.Info("paid", "card", "4111", slog.Group("req", "pan", "5500", "amount", 12), "account", account{"FR76", "bob"})
+
+	if out := buf.String(); strings.Contains(out, "4111") || strings.Contains(out, "5500") {
+		t.Fatalf("output = %s, want no plaintext", out)
+	}
+	entry := decodeLines(t, buf)[0]
+	if mustDecrypt(t, entry["card"].(string)) != "4111" || mustDecrypt(t, entry["req.pan"].(string)) != "5500" {
+		t.Errorf("entry = %v, want the attributes of the keys encrypted", entry)
+	}
+	if entry["req.amount"] != float64(12) {
+		t.Errorf("amount = %v, want the other attributes as is", entry["req.amount"])
+	}
+	if acc := entry["account"].(map[string]interface{}); acc["Name"] != "bob" || mustDecrypt(t, acc["IBAN"].(string)) != "FR76" {
+		t.Errorf("account = %v, want only the tagged fields of the struct encrypted", acc)
+	}
+}
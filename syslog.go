@@ -0,0 +1,37 @@
+//go:build !windows && !plan9 && !binary_log
+
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// NewSyslogWriter connects to the syslog daemon at addr over network, or to the local daemon
+// when network is empty, and returns a writer sending each entry with tag at the severity
+// matching its level: Warning for Warn, Err for Error, Emerg for Fatal and Crit for Panic.
+// When a write fails, e.g. after the daemon restarted, the writer reconnects and retries once.
+func NewSyslogWriter(network, addr, tag string) (zerolog.LevelWriter, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	return zerolog.SyslogLevelWriter(w), nil
+}
+
+// WithSyslog makes the logger write to syslog through a NewSyslogWriter. When the daemon can't be
+// reached, the error is printed to stderr and the logger keeps its other output.
+// The severities are lost behind WithAsyncWriter, every entry being sent at Info.
+func WithSyslog(network, addr, tag string) Option {
+	return func(o *options) {
+		w, err := NewSyslogWriter(network, addr, tag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: syslog unavailable: %v\n", err)
+			return
+		}
+		o.writer = w
+	}
+}
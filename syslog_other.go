@@ -0,0 +1,15 @@
+//go:build windows || plan9 || binary_log
+
+package logger
+
+import "github.com/rs/zerolog"
+
+// NewSyslogWriter returns ErrSyslogUnsupported, syslog being unavailable on this platform.
+func NewSyslogWriter(network, addr, tag string) (zerolog.LevelWriter, error) {
+	return nil, ErrSyslogUnsupported
+}
+
+// WithSyslog leaves the output of the logger unchanged, syslog being unavailable on this platform.
+func WithSyslog(network, addr, tag string) Option {
+	return func(o *options) {}
+}
@@ -0,0 +1,48 @@
+//go:build !windows && !plan9 && !binary_log
+
+package logger
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithSyslog(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	l := NewLogger("test", WithSyslog("udp", pc.LocalAddr().String(), "app"))
+	l.Info().Msg("info")
+	l.Warn().Msg("warn")
+	l.Error().Msg("error")
+
+	// the priority is the user facility, 1, times 8 plus the severity
+	for _, want := range []struct{ priority, message string }{
+		{"<14>", "info"},
+		{"<12>", "warn"},
+		{"<11>", "error"},
+	} {
+		buf := make([]byte, 2048)
+		pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(buf[:n])
+		if !strings.HasPrefix(got, want.priority) || !strings.Contains(got, " app[") ||
+			!strings.Contains(got, `"message":"`+want.message+`"`) {
+			t.Errorf("got %s, want the %s entry at priority %s tagged app", got, want.message, want.priority)
+		}
+	}
+}
+
+func TestNewSyslogWriterUnreachable(t *testing.T) {
+	if _, err := NewSyslogWriter("invalid", "127.0.0.1:0", "app"); err == nil {
+		t.Error("got no error for an unknown network")
+	}
+}
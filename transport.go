@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// HeaderRequestID is the header carrying the request ID to the services called through LoggingTransport.
+const HeaderRequestID = "X-Request-ID"
+
+// maxTransportBody is the number of bytes of a body logged by LoggingTransport, the rest is left out.
+const maxTransportBody = 64 << 10
+
+var transportBodies atomic.Bool
+
+// LogTransportBodies sets whether LoggingTransport logs the request and response bodies, encrypted
+// with the key set by SetKeyEncrypt. The bodies are never logged when no key is set.
+func LogTransportBodies(enabled bool) {
+	transportBodies.Store(enabled)
+}
+
+// LoggingTransport returns a transport logging each request sent through base, http.DefaultTransport
+// when nil, with its method, URL, status and latency, and the bodies when enabled by LogTransportBodies.
+// The request ID of the request context is sent under HeaderRequestID, unless the header is already set.
+func LoggingTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &loggingTransport{base: base}
+}
+
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends req through the base transport and logs it on completion.
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	withBodies := transportBodies.Load() && keyEncrypt != nil && *keyEncrypt != "" && !encryptionDisabled.Load()
+
+	// a RoundTripper must not modify the request, the header and body are set on a clone
+	requestID := req.Header.Get(HeaderRequestID)
+	if info := GetRequestIdByContext(req.Context()); requestID == "" && info != nil && info.RequestID != "" {
+		requestID = info.RequestID
+		req = req.Clone(req.Context())
+		req.Header.Set(HeaderRequestID, requestID)
+	}
+
+	var reqBody []byte
+	if withBodies && req.Body != nil && req.Body != http.NoBody {
+		var body io.ReadCloser
+		reqBody, body = peekBody(req.Body)
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+
+	res, err := t.base.RoundTrip(req)
+
+	l := globalLogger()
+	e := l.Info()
+	if err != nil {
+		e = l.Error().Err(err)
+	} else {
+		e = e.Int(KeyStatus, res.StatusCode)
+	}
+	e = e.Str(KeyMethod, req.Method).
+		Str(KeyURL, req.URL.Redacted()).
+		Latency(start)
+	if requestID != "" {
+		e = e.Str(KeyRequestID, requestID)
+	}
+	if withBodies {
+		names := GetFieldNames()
+		if reqBody != nil {
+			e = e.EncStr(names.RequestBody, string(reqBody))
+		}
+		if res != nil && res.Body != nil && res.Body != http.NoBody {
+			var resBody []byte
+			resBody, res.Body = peekBody(res.Body)
+			e = e.EncStr(names.ResponseBody, string(resBody))
+		}
+	}
+	e.Msg("http client request")
+
+	return res, err
+}
+
+// peekBody reads up to maxTransportBody bytes of body and returns them, along with a body
+// reading them again before the rest of body. Closing the returned body closes body.
+func peekBody(body io.ReadCloser) ([]byte, io.ReadCloser) {
+	head, _ := io.ReadAll(io.LimitReader(body, maxTransportBody))
+	return head, struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), body), body}
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggingTransport(t *testing.T) {
+	setTestKey(t)
+	l, buf := newTestLogger()
+	setGlobalLogger(t, l)
+	LogTransportBodies(true)
+	t.Cleanup(func() { LogTransportBodies(false) })
+
+	var requestIDs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs = append(requestIDs, r.Header.Get(HeaderRequestID))
+		b, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(append([]byte("echo:"), b...))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: LoggingTransport(nil)}
+	ctx := ContextWithTraceInfo(context.Background(), TraceInfo{RequestID: "rid-1"})
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/pay", strings.NewReader("4111"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if string(b) != "echo:4111" {
+			t.Errorf("response body = %q, want it readable by the caller", b)
+		}
+		if req.Header.Get(HeaderRequestID) != "" {
+			t.Errorf("request header modified, want the header set on a clone")
+		}
+	}
+
+	for _, id := range requestIDs {
+		if id != "rid-1" {
+			t.Errorf("request ID header = %q, want rid-1", id)
+		}
+	}
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want one per call", len(entries))
+	}
+	names := GetFieldNames()
+	for _, entry := range entries {
+		if entry[KeyMethod] != http.MethodPost || entry[KeyURL] != srv.URL+"/pay" ||
+			entry[KeyStatus] != float64(http.StatusAccepted) || entry[KeyRequestID] != "rid-1" {
+			t.Errorf("entry %v, want the method, URL, status and request ID", entry)
+		}
+		if _, ok := entry[KeyLatency]; !ok {
+			t.Errorf("entry %v, want the latency", entry)
+		}
+		if got := mustDecrypt(t, entry[names.RequestBody].(string)); got != "4111" {
+			t.Errorf("request body = %q, want 4111 encrypted", got)
+		}
+		if got := mustDecrypt(t, entry[names.ResponseBody].(string)); got != "echo:4111" {
+			t.Errorf("response body = %q, want echo:4111 encrypted", got)
+		}
+	}
+}
+
+func TestLoggingTransportError(t *testing.T) {
+	l, buf := newTestLogger()
+	setGlobalLogger(t, l)
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	client := &http.Client{Transport: LoggingTransport(nil)}
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Fatal("got no error from a closed server")
+	}
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 || entries[0]["level"] != "error" || entries[0]["error"] == nil {
+		t.Fatalf("entries %v, want one error entry", entries)
+	}
+	if _, ok := entries[0][GetFieldNames().RequestBody]; ok {
+		t.Errorf("entry %v, want no body unless enabled", entries[0])
+	}
+}
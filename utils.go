@@ -1,16 +1,35 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/http"
+	"path"
+	"reflect"
 	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
 )
 
 // TraceInfo contains trace information for a request.
+// Baggage holds the other values propagated along with the request, like the tenant or correlation IDs.
 type TraceInfo struct {
-	RequestID string `json:"request_id"`
+	RequestID string            `json:"request_id"`
+	TraceID   string            `json:"trace_id,omitempty"`
+	Baggage   map[string]string `json:"baggage,omitempty"`
 }
 
 // GetFullStack returns the file and function information from the current stacktrace.
@@ -20,15 +39,203 @@ func GetFullStack() string {
 	stack := fmt.Sprintf("%s", buf[0:stackSize])
 	stackTemp := strings.Split(stack, "\n")
 	if len(stackTemp) > 6 {
-		stackFile := fmt.Sprintf("file: %s, func: %s", strings.TrimSpace(stackTemp[6]), strings.TrimSpace(stackTemp[5]))
+		fn := strings.TrimSpace(stackTemp[5])
+		file := strings.TrimSpace(stackTemp[6])
+		// the file line is "path:line +offset"
+		if i := strings.LastIndex(file, ":"); i >= 0 {
+			name := fn
+			if j := strings.LastIndex(name, "("); j >= 0 {
+				name = name[:j]
+			}
+			file = trimCallerFile(file[:i], name) + file[i:]
+		}
+		stackFile := fmt.Sprintf("file: %s, func: %s", file, fn)
 		return stackFile
 	}
 	return "stacktrace unavailable"
 }
 
-// AnyToString converts any value to a string. If the value is a string or []byte, it returns it directly; otherwise, it marshals the value to JSON.
+// CallerTrimMode is how the file paths of the callers are trimmed in the logs.
+type CallerTrimMode int32
+
+const (
+	// CallerTrimFull keeps the absolute file path.
+	CallerTrimFull CallerTrimMode = iota
+	// CallerTrimBase keeps the file name only.
+	CallerTrimBase
+	// CallerTrimPackage keeps the path from the root of the module of the caller, e.g. "loggrpc/stream.go".
+	// Outside of the modules of the build, it keeps the path after the last /pkg/ directory,
+	// or else the file in its directory.
+	CallerTrimPackage
+)
+
+var callerTrim atomic.Int32
+
+// SetCallerTrim sets how GetCaller, GetFullStack, Event.Caller and the stack added by
+// WithAutoStackOnError trim the file paths, which are absolute by default.
+func SetCallerTrim(mode CallerTrimMode) {
+	callerTrim.Store(int32(mode))
+}
+
+// trimCallerFile trims the path file of the function fn as set by SetCallerTrim.
+// The paths reported by runtime use forward slashes on every OS.
+func trimCallerFile(file, fn string) string {
+	switch CallerTrimMode(callerTrim.Load()) {
+	case CallerTrimBase:
+		return path.Base(file)
+	case CallerTrimPackage:
+		pkg := funcPackage(fn)
+		for _, mod := range modulePaths() {
+			if pkg == mod {
+				return path.Base(file)
+			}
+			if strings.HasPrefix(pkg, mod+"/") {
+				return pkg[len(mod)+1:] + "/" + path.Base(file)
+			}
+		}
+		if i := strings.LastIndex(file, "/pkg/"); i >= 0 {
+			return file[i+len("/pkg/"):]
+		}
+		return path.Join(path.Base(path.Dir(file)), path.Base(file))
+	}
+	return file
+}
+
+// funcPackage returns the import path of the package of the function named fn, e.g.
+// "github.com/rs/zerolog" for "github.com/rs/zerolog.(*Event).Msg".
+func funcPackage(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	if dot := strings.Index(fn[slash+1:], "."); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
+
+// modulePaths returns the paths of the modules of the build, the longest first
+// so that a nested module matches before its parent.
+var modulePaths = sync.OnceValue(func() []string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	paths := []string{bi.Main.Path}
+	for _, dep := range bi.Deps {
+		paths = append(paths, dep.Path)
+	}
+	sort.Slice(paths, func(i, j int) bool { return len(paths[i]) > len(paths[j]) })
+	return paths
+})
+
+// stackHook adds the stack of the caller under key to the events at Error level and above.
+type stackHook struct {
+	key string
+}
+
+// Run implements zerolog.Hook.
+func (h stackHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level >= zerolog.ErrorLevel && level <= zerolog.PanicLevel {
+		e.Str(h.key, eventCallerStack())
+	}
+}
+
+// eventCallerStack returns the file and function information of the code that sent the event,
+// in the format of GetFullStack. It must be called by a hook Run method, the frames of zerolog
+// and of the Event wrappers above it being skipped.
+func eventCallerStack() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/rs/zerolog.") &&
+			!strings.HasPrefix(frame.Function, eventFuncPrefix) {
+			return fmt.Sprintf("file: %s:%d, func: %s", trimCallerFile(frame.File, frame.Function), frame.Line, frame.Function)
+		}
+		if !more {
+			return "stacktrace unavailable"
+		}
+	}
+}
+
+// goroutineHook adds the ID of the goroutine sending the event under KeyGoroutineID.
+type goroutineHook struct{}
+
+// Run implements zerolog.Hook.
+func (goroutineHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	if gid, ok := goroutineID(); ok {
+		e.Uint64(KeyGoroutineID, gid)
+	}
+}
+
+// goroutineID parses the ID of the current goroutine from the first line of its stack,
+// "goroutine <id> [<status>]:".
+func goroutineID() (uint64, bool) {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf, ok := bytes.CutPrefix(buf, []byte("goroutine "))
+	if !ok {
+		return 0, false
+	}
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	gid, err := strconv.ParseUint(string(buf), 10, 64)
+	return gid, err == nil
+}
+
+// eventFuncPrefix is the prefix of the names of the Event methods.
+var eventFuncPrefix = reflect.TypeOf(Event{}).PkgPath() + ".(*Event)."
+
+// AnyToString converts any value to a string. If the value is a string or []byte, it returns it directly.
+// A json.Marshaler is marshaled with its own MarshalJSON and a non-struct fmt.Stringer (e.g. time.Duration
+// or a custom enum) uses its String method; otherwise, it marshals the value to JSON.
+// Nil values, including typed nil pointers, maps and slices, return an empty string.
+// The string is truncated to the length set by SetMaxFieldLength.
 func AnyToString(value any) (string, error) {
-	if value == nil {
+	str, err := anyToString(value)
+	return truncateField(str), err
+}
+
+var maxFieldLength atomic.Int64
+
+// SetMaxFieldLength sets the length in bytes past which AnyToString truncates its strings,
+// with a "...[truncated N bytes]" suffix. The bodies stored by the Echo and Fiber setters, holding
+// ciphertexts that can't be decrypted once cut, are replaced by a "[truncated N bytes]" marker instead.
+// A length of 0 or less, the default, disables the truncation.
+func SetMaxFieldLength(n int) {
+	maxFieldLength.Store(int64(max(n, 0)))
+}
+
+// truncateField cuts s to the length set by SetMaxFieldLength, at a rune boundary.
+func truncateField(s string) string {
+	n := int(maxFieldLength.Load())
+	if n == 0 || len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", s[:n], len(s)-n)
+}
+
+// truncateEncrypted is truncateField for a string holding ciphertexts, replacing it as a whole
+// with a marker, unless no key is set or encryption is disabled.
+func truncateEncrypted(s string) string {
+	n := int(maxFieldLength.Load())
+	if n == 0 || len(s) <= n {
+		return s
+	}
+
+	if keyEncrypt == nil || *keyEncrypt == "" || encryptionDisabled.Load() {
+		return truncateField(s)
+	}
+	return fmt.Sprintf("[truncated %d bytes]", len(s))
+}
+
+// anyToString is AnyToString without the truncation.
+func anyToString(value any) (string, error) {
+	if value == nil || isNilValue(reflect.ValueOf(value)) {
 		return "", nil
 	}
 
@@ -40,6 +247,14 @@ func AnyToString(value any) (string, error) {
 		return string(str), nil
 	}
 
+	// json.Marshal calls MarshalJSON and takes care of nil receivers
+	if _, ok := value.(json.Marshaler); !ok {
+		// structs are serialized so that their fields are kept
+		if stringer, ok := value.(fmt.Stringer); ok && isStringerKind(reflect.ValueOf(value)) {
+			return stringer.String(), nil
+		}
+	}
+
 	byteValue, err := json.Marshal(value)
 	if err != nil {
 		return "", err
@@ -48,9 +263,132 @@ func AnyToString(value any) (string, error) {
 	return string(byteValue), nil
 }
 
+// FlattenToMap flattens input into a map of dotted keys, e.g. "user.contact.email", for Event.Fields,
+// with its tagged fields encrypted first as by EncryptInterface. The struct fields are named by their
+// json tags, skipping those omitted from the JSON encoding, the map values by their keys and the fields
+// of an embedded struct are promoted as by encoding/json. The keys start with prefix, if any.
+// The slices and the json.Marshaler or encoding.TextMarshaler values, like time.Time, are kept whole.
+// It returns nil when the tagged fields can't be encrypted.
+func FlattenToMap(input interface{}, prefix string) map[string]interface{} {
+	encrypted, err := EncryptInterface(input)
+	if err != nil {
+		return nil
+	}
+
+	flat := make(map[string]interface{})
+	flattenInto(flat, reflect.ValueOf(encrypted), prefix)
+	return flat
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// flattenInto adds v to flat under key, or its fields and map values under the dotted keys below key.
+func flattenInto(flat map[string]interface{}, v reflect.Value, key string) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			flat[key] = nil
+			return
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		flat[key] = nil
+		return
+	}
+
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		flat[key] = v.Interface()
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, ok := jsonName(f)
+			field := v.Field(i)
+			// the values of unexported fields can't be read, nor those of a nil embedded pointer
+			if !ok || !f.IsExported() || (strings.Contains(f.Tag.Get("json"), ",omitempty") && field.IsZero()) {
+				continue
+			}
+
+			if f.Anonymous && f.Tag.Get("json") == "" && reflect.Indirect(field).Kind() == reflect.Struct {
+				flattenInto(flat, field, key)
+				continue
+			}
+			flattenInto(flat, field, dottedKey(key, name))
+		}
+	case v.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		iter := v.MapRange()
+		for iter.Next() {
+			flattenInto(flat, iter.Value(), dottedKey(key, iter.Key().String()))
+		}
+	default:
+		flat[key] = v.Interface()
+	}
+}
+
+// dottedKey returns name below prefix.
+func dottedKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// isStringerKind reports whether a fmt.Stringer value v should be converted with its String method.
+func isStringerKind(v reflect.Value) bool {
+	return reflect.Indirect(v).Kind() != reflect.Struct
+}
+
+// isNilValue reports whether v is a nil pointer, map, slice or interface.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// contextKey is the type of the context keys of this package, so they can't collide with the keys of other packages.
+type contextKey int
+
+const (
+	traceInfoKey contextKey = iota
+	requestBodyKey
+	responseBodyKey
+)
+
+// WithRequestBody returns a copy of ctx carrying the request body to log.
+func WithRequestBody(ctx context.Context, body string) context.Context {
+	return context.WithValue(ctx, requestBodyKey, body)
+}
+
+// RequestBodyFromContext returns the request body stored by WithRequestBody.
+func RequestBodyFromContext(ctx context.Context) (string, bool) {
+	body, ok := ctx.Value(requestBodyKey).(string)
+	return body, ok
+}
+
+// WithResponseBody returns a copy of ctx carrying the response body to log.
+func WithResponseBody(ctx context.Context, body string) context.Context {
+	return context.WithValue(ctx, responseBodyKey, body)
+}
+
+// ResponseBodyFromContext returns the response body stored by WithResponseBody.
+func ResponseBodyFromContext(ctx context.Context) (string, bool) {
+	body, ok := ctx.Value(responseBodyKey).(string)
+	return body, ok
+}
+
 // GetRequestIdByContext retrieves TraceInfo from the context, returns nil if not found or wrong type.
 func GetRequestIdByContext(ctx context.Context) *TraceInfo {
-	value := ctx.Value(KeyTraceInfo)
+	value := ctx.Value(traceInfoKey)
 	traceInfo, ok := value.(TraceInfo)
 	if !ok {
 		return nil
@@ -58,8 +396,181 @@ func GetRequestIdByContext(ctx context.Context) *TraceInfo {
 	return &traceInfo
 }
 
+// ContextWithTraceInfo returns a copy of ctx carrying traceInfo, as read by GetRequestIdByContext.
+func ContextWithTraceInfo(ctx context.Context, traceInfo TraceInfo) context.Context {
+	return context.WithValue(ctx, traceInfoKey, traceInfo)
+}
+
+type contextField struct {
+	key    interface{}
+	logKey string
+}
+
+var (
+	contextFieldsMu sync.RWMutex
+	contextFields   []contextField
+)
+
+// RegisterContextField registers the context key whose value AddTraceInfoContextRequest and
+// WithContextFields add under logKey, e.g. a tenant or locale stored by a middleware.
+// Registering a key again replaces its logKey. The fields are added in registration order.
+func RegisterContextField(key interface{}, logKey string) {
+	contextFieldsMu.Lock()
+	defer contextFieldsMu.Unlock()
+
+	for i, f := range contextFields {
+		if f.key == key {
+			contextFields[i].logKey = logKey
+			return
+		}
+	}
+	contextFields = append(contextFields, contextField{key: key, logKey: logKey})
+}
+
+// addContextFields adds to c the values of ctx registered with RegisterContextField,
+// skipping the missing and nil ones.
+func addContextFields(c zerolog.Context, ctx context.Context) zerolog.Context {
+	contextFieldsMu.RLock()
+	defer contextFieldsMu.RUnlock()
+
+	for _, f := range contextFields {
+		value := ctx.Value(f.key)
+		if value == nil {
+			continue
+		}
+		if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+			continue
+		}
+		c = c.Interface(f.logKey, value)
+	}
+	return c
+}
+
+// Merge returns t updated with other: a non-empty request or trace ID of other overrides t's, and the baggage
+// is the union of both, other's entries overriding t's on the same key. Neither baggage is modified.
+func (t TraceInfo) Merge(other TraceInfo) TraceInfo {
+	if other.RequestID != "" {
+		t.RequestID = other.RequestID
+	}
+	if other.TraceID != "" {
+		t.TraceID = other.TraceID
+	}
+
+	if len(other.Baggage) > 0 {
+		baggage := make(map[string]string, len(t.Baggage)+len(other.Baggage))
+		maps.Copy(baggage, t.Baggage)
+		maps.Copy(baggage, other.Baggage)
+		t.Baggage = baggage
+	}
+	return t
+}
+
+// MergeTraceInfo returns a copy of ctx whose TraceInfo is the TraceInfo of ctx merged with traceInfo,
+// as by TraceInfo.Merge.
+func MergeTraceInfo(ctx context.Context, traceInfo TraceInfo) context.Context {
+	var merged TraceInfo
+	if info := GetRequestIdByContext(ctx); info != nil {
+		merged = *info
+	}
+	return ContextWithTraceInfo(ctx, merged.Merge(traceInfo))
+}
+
+// WithBaggage returns a copy of ctx whose TraceInfo has the baggage entry key set to value,
+// keeping its request ID and other entries. The TraceInfo of ctx is left unchanged.
+func WithBaggage(ctx context.Context, key, value string) context.Context {
+	var traceInfo TraceInfo
+	if info := GetRequestIdByContext(ctx); info != nil {
+		traceInfo = *info
+	}
+
+	baggage := make(map[string]string, len(traceInfo.Baggage)+1)
+	for k, v := range traceInfo.Baggage {
+		baggage[k] = v
+	}
+	baggage[key] = value
+	traceInfo.Baggage = baggage
+
+	return ContextWithTraceInfo(ctx, traceInfo)
+}
+
+// TraceHeaderNames are the names of the incoming headers, or gRPC metadata keys, the trace information
+// is read from, each list tried in order until a non-empty value.
+type TraceHeaderNames struct {
+	RequestID []string // defaults to HeaderRequestID then HeaderCorrelationID
+	TraceID   []string // defaults to HeaderTraceID
+}
+
+// The default headers the trace information is read from, along with HeaderRequestID.
+const (
+	HeaderCorrelationID = "X-Correlation-ID"
+	HeaderTraceID       = "X-Trace-ID"
+)
+
+func defaultTraceHeaderNames() TraceHeaderNames {
+	return TraceHeaderNames{
+		RequestID: []string{HeaderRequestID, HeaderCorrelationID},
+		TraceID:   []string{HeaderTraceID},
+	}
+}
+
+var traceHeaderNames atomic.Pointer[TraceHeaderNames]
+
+// SetTraceHeaderNames sets the headers the trace information is read from, by the HTTP middlewares
+// and the gRPC interceptors. The middlewares send the request ID back under the first RequestID header.
+// An empty list keeps its default.
+func SetTraceHeaderNames(names TraceHeaderNames) {
+	defaults := defaultTraceHeaderNames()
+	if len(names.RequestID) == 0 {
+		names.RequestID = defaults.RequestID
+	}
+	if len(names.TraceID) == 0 {
+		names.TraceID = defaults.TraceID
+	}
+	traceHeaderNames.Store(&names)
+}
+
+// GetTraceHeaderNames returns the headers set by SetTraceHeaderNames, or the defaults.
+func GetTraceHeaderNames() TraceHeaderNames {
+	if names := traceHeaderNames.Load(); names != nil {
+		return *names
+	}
+	return defaultTraceHeaderNames()
+}
+
+// TraceInfoFromHeader returns the trace information sent in h, read from the headers set by
+// SetTraceHeaderNames, with a request ID generated by NewRequestID when none is sent.
+func TraceInfoFromHeader(h http.Header) TraceInfo {
+	return traceInfoFrom(h.Get)
+}
+
+// traceInfoFrom is TraceInfoFromHeader reading the headers with get.
+func traceInfoFrom(get func(name string) string) TraceInfo {
+	names := GetTraceHeaderNames()
+	first := func(names []string) string {
+		for _, name := range names {
+			if value := get(name); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	traceInfo := TraceInfo{RequestID: first(names.RequestID), TraceID: first(names.TraceID)}
+	if traceInfo.RequestID == "" {
+		traceInfo.RequestID = NewRequestID()
+	}
+	return traceInfo
+}
+
+// NewRequestID returns a random 16 bytes request ID encoded in hex.
+func NewRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
 func EncryptLog[T any](data T) (T, error) {
-	if keyEncrypt == nil || *keyEncrypt == "" {
+	if keyEncrypt == nil || *keyEncrypt == "" || isNilInput(data) || encryptionDisabled.Load() {
 		return data, nil
 	}
 
@@ -67,7 +578,7 @@ func EncryptLog[T any](data T) (T, error) {
 	case string:
 		res, err := Encrypt(v, *keyEncrypt)
 		if err != nil {
-			return data, err
+			return abortedEncrypt(data, err)
 		}
 
 		var result interface{} = res
@@ -75,18 +586,19 @@ func EncryptLog[T any](data T) (T, error) {
 	case *string:
 		res, err := Encrypt(*v, *keyEncrypt)
 		if err != nil {
-			return data, err
+			return abortedEncrypt(data, err)
 		}
 
 		var result interface{} = &res
 		return result.(T), nil
 	}
 
-	return InterfaceEncryptTag(data, *keyEncrypt, TagNameEncrypt, TagValEncrypt)
+	tagName, tagVal := defaultEncryptTag()
+	return InterfaceEncryptTag(data, *keyEncrypt, tagName, tagVal)
 }
 
 func EncryptInterface(data interface{}) (interface{}, error) {
-	if keyEncrypt == nil || *keyEncrypt == "" {
+	if keyEncrypt == nil || *keyEncrypt == "" || isNilInput(data) || encryptionDisabled.Load() {
 		return data, nil
 	}
 
@@ -97,5 +609,267 @@ func EncryptInterface(data interface{}) (interface{}, error) {
 		return Encrypt(*v, *keyEncrypt)
 	}
 
-	return InterfaceEncryptTagInterface(data, *keyEncrypt, TagNameEncrypt, TagValEncrypt)
+	tagName, tagVal := defaultEncryptTag()
+	return InterfaceEncryptTagInterface(data, *keyEncrypt, tagName, tagVal)
+}
+
+// Encryptable marks the types whose values EncryptIfMarked encrypts, e.g. with an empty
+// `func (Card) Sensitive() {}` method.
+type Encryptable interface {
+	Sensitive()
+}
+
+var encryptableType = reflect.TypeOf((*Encryptable)(nil)).Elem()
+
+// EncryptIfMarked encrypts input as EncryptInterface when its type, or the element type of a slice,
+// implements Encryptable with a value or pointer receiver. Any other input is returned unchanged,
+// without being walked by reflection.
+func EncryptIfMarked(input interface{}) (interface{}, error) {
+	t := reflect.TypeOf(input)
+	if t == nil {
+		return input, nil
+	}
+
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !t.Implements(encryptableType) && !reflect.PointerTo(t).Implements(encryptableType) {
+		return input, nil
+	}
+
+	return EncryptInterface(input)
+}
+
+// decryptJSONValues decrypts every string value of the JSON document data that is a valid ciphertext for key,
+// leaving the other values untouched. A data that isn't JSON is decrypted as a whole when possible.
+func decryptJSONValues(data, key string) (string, error) {
+	var doc interface{}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		if plaintext, err := Decrypt(data, key); err == nil {
+			return plaintext, nil
+		}
+		return data, nil
+	}
+
+	byteValue, err := json.Marshal(decryptJSONNode(doc, key))
+	if err != nil {
+		return "", err
+	}
+
+	return string(byteValue), nil
+}
+
+// DecryptLogLine decrypts the values encrypted with key of the JSON log line jsonLine and returns it re-serialized.
+// Only the top-level keys mapped to true in tagMapping are decrypted, failing when one isn't a valid ciphertext.
+// With an empty tagMapping, every string value that is a valid ciphertext is decrypted.
+func DecryptLogLine(jsonLine string, key string, tagMapping map[string]bool) (string, error) {
+	var doc map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(jsonLine))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return "", fmt.Errorf("log line is not a JSON object: %w", err)
+	}
+
+	if len(tagMapping) == 0 {
+		decryptJSONNode(doc, key)
+	}
+
+	for name, encrypted := range tagMapping {
+		value, ok := doc[name].(string)
+		if !encrypted || !ok {
+			continue
+		}
+
+		plaintext, err := Decrypt(value, key)
+		if err != nil {
+			return "", fmt.Errorf("decrypt field %q: %w", name, err)
+		}
+		doc[name] = plaintext
+	}
+
+	byteValue, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return string(byteValue), nil
+}
+
+// DecryptAndFormat decrypts the top-level encryptedKeys of the JSON log line jsonLine as DecryptLogLine does
+// and returns it as indented JSON, for display. A decrypted value that is itself a JSON object or array,
+// like a logged body, is indented along with the line. The keys absent from the line are skipped.
+func DecryptAndFormat(jsonLine string, key string, encryptedKeys []string) (string, error) {
+	var doc map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(jsonLine))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return "", fmt.Errorf("log line is not a JSON object: %w", err)
+	}
+
+	for _, name := range encryptedKeys {
+		value, ok := doc[name].(string)
+		if !ok {
+			continue
+		}
+
+		plaintext, err := Decrypt(value, key)
+		if err != nil {
+			return "", fmt.Errorf("decrypt field %q: %w", name, err)
+		}
+		doc[name] = plaintext
+
+		var nested interface{}
+		nestedDecoder := json.NewDecoder(strings.NewReader(plaintext))
+		nestedDecoder.UseNumber()
+		if trimmed := strings.TrimSpace(plaintext); trimmed != "" && (trimmed[0] == '{' || trimmed[0] == '[') &&
+			nestedDecoder.Decode(&nested) == nil && !nestedDecoder.More() {
+			doc[name] = nested
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// EncryptedJSONFields returns the JSON keys of the fields of the struct, or pointer to struct, input
+// tagged `tagName:"tagVal"`, as the tagMapping of DecryptLogLine for the lines logging input's fields.
+// The fields promoted from embedded structs are included, while the nested structs aren't.
+func EncryptedJSONFields(input interface{}, tagName, tagVal string) map[string]bool {
+	fields := make(map[string]bool)
+
+	t := reflect.TypeOf(input)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Struct {
+		addEncryptedJSONFields(t, tagName, tagVal, fields)
+	}
+
+	return fields
+}
+
+// addEncryptedJSONFields adds the JSON keys of the tagged fields of the struct type t to fields.
+func addEncryptedJSONFields(t reflect.Type, tagName, tagVal string, fields map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		// the walker skips the unexported and the JSON omitted fields
+		name, ok := jsonName(f)
+		if f.PkgPath != "" || !ok {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && f.Tag.Get("json") == "" && ft.Kind() == reflect.Struct {
+			addEncryptedJSONFields(ft, tagName, tagVal, fields)
+			continue
+		}
+
+		if f.Tag.Get(tagName) == tagVal {
+			fields[name] = true
+		}
+	}
+}
+
+// DecryptJSONPointers decrypts the string values encrypted with key at the JSON Pointers (RFC 6901) of the
+// JSON document jsonLine, e.g. "/request/card" or "/items/0/pan", and returns it re-serialized.
+// Pointers that don't resolve to a string are skipped, while a value failing to decrypt is an error.
+func DecryptJSONPointers(jsonLine string, key string, pointers []string) (string, error) {
+	var doc interface{}
+	decoder := json.NewDecoder(strings.NewReader(jsonLine))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return "", fmt.Errorf("log line is not JSON: %w", err)
+	}
+
+	for _, pointer := range pointers {
+		if pointer != "" && pointer[0] != '/' {
+			return "", fmt.Errorf("invalid JSON pointer %q", pointer)
+		}
+
+		value, set := resolveJSONPointer(&doc, pointer)
+		str, ok := value.(string)
+		if set == nil || !ok {
+			continue
+		}
+
+		plaintext, err := Decrypt(str, key)
+		if err != nil {
+			return "", fmt.Errorf("decrypt %q: %w", pointer, err)
+		}
+		set(plaintext)
+	}
+
+	byteValue, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return string(byteValue), nil
+}
+
+// resolveJSONPointer returns the value of the decoded JSON document doc at pointer
+// and a function replacing it, nil when the pointer doesn't resolve.
+func resolveJSONPointer(doc *interface{}, pointer string) (interface{}, func(interface{})) {
+	value, set := *doc, func(v interface{}) { *doc = v }
+	if pointer == "" {
+		return value, set
+	}
+
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = unescape.Replace(token)
+
+		switch node := value.(type) {
+		case map[string]interface{}:
+			item, ok := node[token]
+			if !ok {
+				return nil, nil
+			}
+			value, set = item, func(v interface{}) { node[token] = v }
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) || (len(token) > 1 && token[0] == '0') {
+				return nil, nil
+			}
+			value, set = node[index], func(v interface{}) { node[index] = v }
+		default:
+			return nil, nil
+		}
+	}
+
+	return value, set
+}
+
+// decryptJSONNode decrypts the string values of a decoded JSON node, recursing into objects and arrays.
+func decryptJSONNode(node interface{}, key string) interface{} {
+	switch v := node.(type) {
+	case string:
+		if plaintext, err := Decrypt(v, key); err == nil && utf8.ValidString(plaintext) {
+			return plaintext
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = decryptJSONNode(item, key)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = decryptJSONNode(item, key)
+		}
+	}
+	return node
 }
@@ -0,0 +1,568 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testEnum int
+
+func (e testEnum) String() string { return [...]string{"pending", "done"}[e] }
+
+type testJSONMarshaler struct{}
+
+func (testJSONMarshaler) MarshalJSON() ([]byte, error) { return []byte(`"custom"`), nil }
+
+type testUser struct {
+	Name string `json:"name"`
+}
+
+func TestAnyToString(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"string", "plain", "plain"},
+		{"bytes", []byte("raw"), "raw"},
+		{"duration", 1500 * time.Millisecond, "1.5s"},
+		{"stringer", testEnum(1), "done"},
+		{"json marshaler", testJSONMarshaler{}, `"custom"`},
+		{"struct", testUser{Name: "bob"}, `{"name":"bob"}`},
+		{"number", 42, "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AnyToString(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("AnyToString(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnyToStringTypedNil(t *testing.T) {
+	for _, value := range []any{(*testUser)(nil), map[string]int(nil), []string(nil)} {
+		got, err := AnyToString(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "" {
+			t.Errorf("AnyToString(%T nil) = %q, want an empty string", value, got)
+		}
+	}
+
+	got, err := AnyToString(&testUser{Name: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"name":"bob"}` {
+		t.Errorf("AnyToString(&testUser) = %q, want the serialized user", got)
+	}
+}
+
+func TestBodyContextAccessors(t *testing.T) {
+	ctx := WithResponseBody(WithRequestBody(context.Background(), "req"), "resp")
+
+	if body, ok := RequestBodyFromContext(ctx); !ok || body != "req" {
+		t.Errorf("RequestBodyFromContext = %q, %t, want req", body, ok)
+	}
+	if body, ok := ResponseBodyFromContext(ctx); !ok || body != "resp" {
+		t.Errorf("ResponseBodyFromContext = %q, %t, want resp", body, ok)
+	}
+	if _, ok := RequestBodyFromContext(context.Background()); ok {
+		t.Error("RequestBodyFromContext found a body in an empty context")
+	}
+}
+
+func TestBodyContextKeysDontCollide(t *testing.T) {
+	// a plain string key of the same text, as another package could use
+	ctx := context.WithValue(context.Background(), KeyRequestBody, "other")
+
+	if body, ok := RequestBodyFromContext(ctx); ok {
+		t.Errorf("RequestBodyFromContext = %q, want the plain string key ignored", body)
+	}
+
+	ctx = WithRequestBody(ctx, "req")
+	if ctx.Value(KeyRequestBody) != "other" {
+		t.Errorf("value under the plain string key = %v, want it unchanged", ctx.Value(KeyRequestBody))
+	}
+}
+
+func TestDecryptLogLine(t *testing.T) {
+	pan, err := Encrypt("4111111111111111", testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := `{"level":"info","amount":12.50,"pan":"` + pan + `","message":"paid"}`
+
+	got, err := DecryptLogLine(line, testKey, map[string]bool{"pan": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"amount":12.50,"level":"info","message":"paid","pan":"4111111111111111"}` {
+		t.Errorf("DecryptLogLine = %s, want the pan decrypted and the numbers kept as is", got)
+	}
+
+	got, err = DecryptLogLine(line, testKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `"pan":"4111111111111111"`) {
+		t.Errorf("DecryptLogLine without a mapping = %s, want the ciphertexts found and decrypted", got)
+	}
+
+	if _, err := DecryptLogLine(`{"pan":"4111"}`, testKey, map[string]bool{"pan": true}); err == nil {
+		t.Error("DecryptLogLine of a plaintext mapped field succeeded, want an error")
+	}
+	if _, err := DecryptLogLine("not json", testKey, nil); err == nil {
+		t.Error("DecryptLogLine of an invalid line succeeded, want an error")
+	}
+}
+
+func TestDecryptAndFormat(t *testing.T) {
+	pan, err := Encrypt("4111111111111111", testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := Encrypt(`{"card":"5500","amount":12.50}`, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := `{"level":"info","pan":"` + pan + `","req_body":"` + body + `","message":"<paid>"}`
+
+	got, err := DecryptAndFormat(line, testKey, []string{"pan", "req_body", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "level": "info",
+  "message": "<paid>",
+  "pan": "4111111111111111",
+  "req_body": {
+    "amount": 12.50,
+    "card": "5500"
+  }
+}`
+	if got != want {
+		t.Errorf("DecryptAndFormat = %s, want %s", got, want)
+	}
+
+	if _, err := DecryptAndFormat(`{"pan":"4111"}`, testKey, []string{"pan"}); err == nil {
+		t.Error("DecryptAndFormat of a plaintext listed key succeeded, want an error")
+	}
+	if _, err := DecryptAndFormat("not json", testKey, nil); err == nil {
+		t.Error("DecryptAndFormat of an invalid line succeeded, want an error")
+	}
+}
+
+func TestDecryptJSONPointers(t *testing.T) {
+	encrypt := func(plaintext string) string {
+		ciphertext, err := Encrypt(plaintext, testKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ciphertext
+	}
+	line := `{"request":{"card":"` + encrypt("4111") + `","a/b":"` + encrypt("slash") + `"},` +
+		`"items":[{"pan":"` + encrypt("5500") + `"},{"pan":"` + encrypt("3400") + `"}],"n":1.0}`
+
+	got, err := DecryptJSONPointers(line, testKey, []string{"/request/card", "/request/a~1b", "/items/1/pan", "/missing", "/items/9/pan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Request map[string]string
+		Items   []map[string]string
+		N       json.Number
+	}
+	if err := json.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Request["card"] != "4111" || doc.Request["a/b"] != "slash" || doc.Items[1]["pan"] != "3400" {
+		t.Errorf("DecryptJSONPointers = %s, want the values at the pointers decrypted", got)
+	}
+	if !IsEncrypted(doc.Items[0]["pan"]) {
+		t.Errorf("items/0/pan = %q, want the value outside the pointers left encrypted", doc.Items[0]["pan"])
+	}
+	if doc.N != "1.0" {
+		t.Errorf("n = %s, want the number kept as is", doc.N)
+	}
+
+	if _, err := DecryptJSONPointers(line, testKey, []string{"request/card"}); err == nil {
+		t.Error("a pointer without the leading slash succeeded, want an error")
+	}
+	if _, err := DecryptJSONPointers(`{"card":"4111"}`, testKey, []string{"/card"}); err == nil {
+		t.Error("a plaintext at a pointer succeeded, want an error")
+	}
+}
+
+// callerOf returns what GetCaller reports when called by a function called by the test.
+func callerOf(l *Logger) string {
+	return l.GetCaller()
+}
+
+// stackOf returns what GetFullStack reports when called by a function called by the test.
+func stackOf() string {
+	return GetFullStack()
+}
+
+func TestSetCallerTrim(t *testing.T) {
+	t.Cleanup(func() { SetCallerTrim(CallerTrimFull) })
+	l := NewNoopLogger()
+
+	const file = "/home/build/go-logging/loggrpc/stream.go"
+	tests := []struct {
+		mode      CallerTrimMode
+		fn        string
+		file      string
+		wantTrim  string
+		wantCalls string
+	}{
+		{CallerTrimFull, "github.com/gotech-hub/go-logging/loggrpc.(*loggedStream).RecvMsg", file, file, "/"},
+		{CallerTrimBase, "github.com/gotech-hub/go-logging/loggrpc.(*loggedStream).RecvMsg", file, "stream.go", "utils_test.go:"},
+		{CallerTrimPackage, "github.com/gotech-hub/go-logging/loggrpc.(*loggedStream).RecvMsg", file, "loggrpc/stream.go", "utils_test.go:"},
+		{CallerTrimPackage, "example.com/app.main", "/src/vendor/pkg/app/main.go", "app/main.go", "utils_test.go:"},
+		{CallerTrimPackage, "main.main", "/src/cmd/tool/main.go", "tool/main.go", "utils_test.go:"},
+	}
+	for _, tt := range tests {
+		SetCallerTrim(tt.mode)
+		if got := trimCallerFile(tt.file, tt.fn); got != tt.wantTrim {
+			t.Errorf("mode %d: trimCallerFile(%s) = %s, want %s", tt.mode, tt.file, got, tt.wantTrim)
+		}
+		if got := callerOf(l); !strings.HasPrefix(got, tt.wantCalls) || !strings.HasSuffix(got, " TestSetCallerTrim") {
+			t.Errorf("mode %d: GetCaller = %s, want it to start with %s", tt.mode, got, tt.wantCalls)
+		}
+	}
+
+	SetCallerTrim(CallerTrimBase)
+	if stack := stackOf(); !strings.HasPrefix(stack, "file: utils_test.go:") {
+		t.Errorf("GetFullStack = %s, want the file name only", stack)
+	}
+}
+
+type markedCard struct {
+	PAN string `encrypt:"true"`
+}
+
+func (markedCard) Sensitive() {}
+
+type pointerMarkedCard struct {
+	PAN string `encrypt:"true"`
+}
+
+func (*pointerMarkedCard) Sensitive() {}
+
+type unmarkedCard struct {
+	PAN string `encrypt:"true"`
+}
+
+func TestEncryptIfMarked(t *testing.T) {
+	setTestKey(t)
+	const pan = "4111111111111111"
+
+	got, err := EncryptIfMarked(markedCard{PAN: pan})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if card := got.(markedCard); mustDecrypt(t, card.PAN) != pan {
+		t.Errorf("marked PAN = %s, want the ciphertext of %s", card.PAN, pan)
+	}
+
+	ptr := &pointerMarkedCard{PAN: pan}
+	got, err = EncryptIfMarked(ptr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if card := got.(*pointerMarkedCard); mustDecrypt(t, card.PAN) != pan {
+		t.Errorf("pointer marked PAN = %s, want the ciphertext of %s", card.PAN, pan)
+	}
+	if ptr.PAN != pan {
+		t.Errorf("input PAN = %s, want it unchanged", ptr.PAN)
+	}
+
+	got, err = EncryptIfMarked([]markedCard{{PAN: pan}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cards := got.([]markedCard); mustDecrypt(t, cards[0].PAN) != pan {
+		t.Errorf("slice PAN = %s, want the ciphertext of %s", cards[0].PAN, pan)
+	}
+
+	for _, input := range []interface{}{unmarkedCard{PAN: pan}, []unmarkedCard{{PAN: pan}}, pan, nil} {
+		got, err := EncryptIfMarked(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, input) {
+			t.Errorf("EncryptIfMarked(%#v) = %#v, want it unchanged", input, got)
+		}
+	}
+}
+
+func TestWithBaggage(t *testing.T) {
+	l, buf := newTestLogger()
+	ctx := ContextWithTraceInfo(context.Background(), TraceInfo{RequestID: "rid-1"})
+	ctx = WithBaggage(ctx, "tenant_id", "acme")
+	child := WithBaggage(ctx, "user_id", "u1")
+
+	l.AddTraceInfoContextRequest(child).Info().Msg("child")
+	l.AddTraceInfoContextRequest(child).Info().Msg("again")
+	l.AddTraceInfoContextRequest(ctx).Info().Msg("parent")
+	l.AddTraceInfoContextRequest(context.Background()).Info().Msg("none")
+
+	entries := decodeLines(t, buf)
+	for _, entry := range entries[:2] {
+		if entry["tenant_id"] != "acme" || entry["user_id"] != "u1" {
+			t.Errorf("%s: entry %v, want each baggage entry as a field", entry["message"], entry)
+		}
+		info, _ := entry[KeyTraceInfo].(map[string]interface{})
+		if info["request_id"] != "rid-1" {
+			t.Errorf("%s: trace info %v, want the request ID kept", entry["message"], info)
+		}
+		if _, ok := info["baggage"]; ok {
+			t.Errorf("%s: trace info %v, want the baggage logged only as fields", entry["message"], info)
+		}
+	}
+	if entries[2]["tenant_id"] != "acme" || entries[2]["user_id"] != nil {
+		t.Errorf("parent entry %v, want the parent baggage only", entries[2])
+	}
+	if _, ok := entries[3][KeyTraceInfo]; ok {
+		t.Errorf("entry %v, want no trace info without one in the context", entries[3])
+	}
+
+	b, err := json.Marshal(TraceInfo{RequestID: "rid-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"request_id":"rid-1"}` {
+		t.Errorf("JSON = %s, want the empty trace ID and baggage omitted", b)
+	}
+}
+
+type testContextKey string
+
+func TestRegisterContextField(t *testing.T) {
+	tenantKey, localeKey, flagsKey := testContextKey("tenant"), testContextKey("locale"), testContextKey("flags")
+	RegisterContextField(tenantKey, "tenant")
+	RegisterContextField(localeKey, "lang")
+	RegisterContextField(localeKey, "locale")
+	RegisterContextField(flagsKey, "flags")
+	t.Cleanup(func() {
+		contextFieldsMu.Lock()
+		defer contextFieldsMu.Unlock()
+		contextFields = slices.DeleteFunc(contextFields, func(f contextField) bool {
+			_, ok := f.key.(testContextKey)
+			return ok
+		})
+	})
+
+	l, buf := newTestLogger()
+	ctx := context.WithValue(context.Background(), tenantKey, "acme")
+	ctx = context.WithValue(ctx, localeKey, "fr-FR")
+	ctx = context.WithValue(ctx, flagsKey, (*[]string)(nil))
+
+	l.AddTraceInfoContextRequest(ctx).Info().Msg("trace")
+	l.WithContextFields(ctx).Info().Msg("fields")
+	l.WithContextFields(context.Background()).Info().Msg("none")
+
+	entries := decodeLines(t, buf)
+	for _, entry := range entries[:2] {
+		if entry["tenant"] != "acme" || entry["locale"] != "fr-FR" {
+			t.Errorf("%s: entry %v, want the registered context values", entry["message"], entry)
+		}
+		if _, ok := entry["lang"]; ok {
+			t.Errorf("%s: entry %v, want the log key replaced", entry["message"], entry)
+		}
+		if _, ok := entry["flags"]; ok {
+			t.Errorf("%s: entry %v, want the nil value skipped", entry["message"], entry)
+		}
+	}
+	if _, ok := entries[2]["tenant"]; ok {
+		t.Errorf("entry %v, want the missing values skipped", entries[2])
+	}
+}
+
+func TestSetMaxFieldLength(t *testing.T) {
+	SetMaxFieldLength(10)
+	t.Cleanup(func() { SetMaxFieldLength(0) })
+
+	tests := []struct {
+		input interface{}
+		want  string
+	}{
+		{"short", "short"},
+		{"exactly 10", "exactly 10"},
+		{"hello world!!", "hello worl...[truncated 3 bytes]"},
+		// the cut falls inside é, left out whole
+		{"123456789é0", "123456789...[truncated 3 bytes]"},
+		{testUser{Name: "bob"}, `{"name":"b...[truncated 4 bytes]`},
+	}
+	for _, tt := range tests {
+		got, err := AnyToString(tt.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("AnyToString(%v) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	setTestKey(t)
+	c := newEchoContext()
+	SetEchoReqEncrLog(c, markedCard{PAN: "4111111111111111"})
+	body, _ := RequestBodyFromContext(c.Request().Context())
+	if !strings.HasPrefix(body, "[truncated ") || strings.Contains(body, ciphertextMarker) {
+		t.Errorf("body = %q, want the ciphertext replaced by a marker", body)
+	}
+
+	SetMaxFieldLength(1000)
+	c = newEchoContext()
+	SetEchoReqEncrLog(c, markedCard{PAN: "4111111111111111"})
+	if body, err := GetEchoReqDecrLog(c); err != nil || body != `{"PAN":"4111111111111111"}` {
+		t.Errorf("body = %q, %v, want it stored whole under the limit", body, err)
+	}
+}
+
+func TestTraceInfoMerge(t *testing.T) {
+	upstream := TraceInfo{RequestID: "up", TraceID: "trace-up", Baggage: map[string]string{"tenant_id": "acme", "user_id": "u1"}}
+
+	tests := []struct {
+		name  string
+		other TraceInfo
+		want  TraceInfo
+	}{
+		{"empty", TraceInfo{}, upstream},
+		{"override", TraceInfo{RequestID: "down"}, TraceInfo{RequestID: "down", TraceID: "trace-up", Baggage: upstream.Baggage}},
+		{"union", TraceInfo{Baggage: map[string]string{"user_id": "u2", "locale": "fr"}}, TraceInfo{
+			RequestID: "up",
+			TraceID:   "trace-up",
+			Baggage:   map[string]string{"tenant_id": "acme", "user_id": "u2", "locale": "fr"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := upstream.Merge(tt.other); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Merge = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+	if len(upstream.Baggage) != 2 || upstream.Baggage["user_id"] != "u1" {
+		t.Errorf("upstream baggage %v, want it unchanged", upstream.Baggage)
+	}
+
+	ctx := ContextWithTraceInfo(context.Background(), upstream)
+	merged := MergeTraceInfo(ctx, TraceInfo{TraceID: "span", Baggage: map[string]string{"locale": "fr"}})
+	got := GetRequestIdByContext(merged)
+	if got.RequestID != "up" || got.TraceID != "span" || got.Baggage["tenant_id"] != "acme" || got.Baggage["locale"] != "fr" {
+		t.Errorf("MergeTraceInfo = %+v, want the trace info of ctx merged", got)
+	}
+	if _, ok := GetRequestIdByContext(ctx).Baggage["locale"]; ok {
+		t.Error("MergeTraceInfo modified the trace info of its context")
+	}
+	if got := GetRequestIdByContext(MergeTraceInfo(context.Background(), TraceInfo{RequestID: "new"})); got.RequestID != "new" {
+		t.Errorf("MergeTraceInfo without trace info = %+v, want the given trace info", got)
+	}
+}
+
+type flatContact struct {
+	Email string `json:"email" encrypt:"true"`
+	Phone string `json:"phone,omitempty"`
+}
+
+type flatUser struct {
+	TraceInfo
+	Name     string            `json:"name"`
+	Contact  *flatContact      `json:"contact"`
+	Labels   map[string]string `json:"labels"`
+	Tags     []string          `json:"tags"`
+	Created  time.Time         `json:"created"`
+	Password string            `json:"-"`
+	internal string
+}
+
+func TestFlattenToMap(t *testing.T) {
+	setTestKey(t)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := flatUser{
+		TraceInfo: TraceInfo{RequestID: "rid-1"},
+		Name:      "bob",
+		Contact:   &flatContact{Email: "bob@example.com"},
+		Labels:    map[string]string{"team": "core"},
+		Tags:      []string{"a", "b"},
+		Created:   created,
+		Password:  "hunter2",
+		internal:  "x",
+	}
+
+	flat := FlattenToMap(input, "user")
+
+	want := map[string]interface{}{
+		"user.request_id":    "rid-1",
+		"user.name":          "bob",
+		"user.contact.email": flat["user.contact.email"],
+		"user.labels.team":   "core",
+		"user.tags":          []string{"a", "b"},
+		"user.created":       created,
+	}
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("FlattenToMap = %v, want %v", flat, want)
+	}
+	if email, _ := flat["user.contact.email"].(string); mustDecrypt(t, email) != "bob@example.com" {
+		t.Errorf("email = %v, want its ciphertext", flat["user.contact.email"])
+	}
+
+	l, buf := newTestLogger()
+	l.Info().Fields(FlattenToMap(input, "")).Msg("flat")
+	if entry := decodeLines(t, buf)[0]; entry["contact.email"] == nil || entry["name"] != "bob" {
+		t.Errorf("entry %v, want the flat fields", entry)
+	}
+
+	if contact, ok := FlattenToMap(&flatUser{}, "")["contact"]; !ok || contact != nil {
+		t.Errorf("contact = %v, want the nil contact kept as nil", contact)
+	}
+}
+
+func TestTraceInfoFromHeader(t *testing.T) {
+	t.Cleanup(func() { SetTraceHeaderNames(TraceHeaderNames{}) })
+
+	header := func(pairs ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i < len(pairs); i += 2 {
+			h.Set(pairs[i], pairs[i+1])
+		}
+		return h
+	}
+	tests := []struct {
+		name  string
+		names TraceHeaderNames
+		h     http.Header
+		want  TraceInfo
+	}{
+		{"request ID", TraceHeaderNames{}, header("X-Request-ID", "rid-1", "X-Correlation-ID", "corr-1", "X-Trace-ID", "trace-1"),
+			TraceInfo{RequestID: "rid-1", TraceID: "trace-1"}},
+		{"correlation ID", TraceHeaderNames{}, header("x-correlation-id", "corr-1"), TraceInfo{RequestID: "corr-1"}},
+		{"configured", TraceHeaderNames{RequestID: []string{"X-Amzn-Trace-Id"}}, header("X-Request-ID", "rid-1", "X-Amzn-Trace-Id", "amzn-1"),
+			TraceInfo{RequestID: "amzn-1"}},
+	}
+	for _, tt := range tests {
+		SetTraceHeaderNames(tt.names)
+		if got := TraceInfoFromHeader(tt.h); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: TraceInfoFromHeader = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	SetTraceHeaderNames(TraceHeaderNames{})
+	first, second := TraceInfoFromHeader(http.Header{}), TraceInfoFromHeader(http.Header{})
+	if len(first.RequestID) != 32 || first.RequestID == second.RequestID {
+		t.Errorf("request IDs %q and %q, want distinct generated IDs without headers", first.RequestID, second.RequestID)
+	}
+}
@@ -0,0 +1,688 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxWalkDepth is the default maximum nesting depth the tag walkers recurse into.
+const DefaultMaxWalkDepth = 32
+
+var maxWalkDepth atomic.Int64 // 0 for DefaultMaxWalkDepth
+
+var nullStringType = reflect.TypeOf(sql.NullString{})
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+var encryptEmpty atomic.Bool
+
+// SetMaxWalkDepth sets the maximum nesting depth the tag walkers recurse into.
+// Walking a value nested deeper than depth returns an error.
+func SetMaxWalkDepth(depth int) {
+	if depth > 0 {
+		maxWalkDepth.Store(int64(depth))
+	}
+}
+
+// currentMaxWalkDepth returns the depth set by SetMaxWalkDepth, or DefaultMaxWalkDepth.
+func currentMaxWalkDepth() int {
+	if depth := maxWalkDepth.Load(); depth > 0 {
+		return int(depth)
+	}
+	return DefaultMaxWalkDepth
+}
+
+// EncryptEmptyFields sets whether the tag walkers encrypt the empty tagged strings.
+// By default they are left empty, instead of logging the ciphertext of "".
+func EncryptEmptyFields(enabled bool) {
+	encryptEmpty.Store(enabled)
+}
+
+var opaqueTypes sync.Map // reflect.Type -> struct{}
+
+func init() {
+	RegisterOpaqueType(reflect.TypeOf(time.Time{}))
+}
+
+// RegisterOpaqueType registers a struct type the tag walkers don't recurse into, e.g. a decimal type
+// whose fields are an implementation detail, and that Copy copies as a whole, its unexported fields
+// included. time.Time is registered by default.
+func RegisterOpaqueType(t reflect.Type) {
+	opaqueTypes.Store(t, struct{}{})
+}
+
+// isOpaqueType reports whether t was registered with RegisterOpaqueType.
+func isOpaqueType(t reflect.Type) bool {
+	_, ok := opaqueTypes.Load(t)
+	return ok
+}
+
+// EncryptFailurePolicy decides what the encrypt walkers return when encrypting a field fails.
+type EncryptFailurePolicy int32
+
+const (
+	// EncryptFailureBlankAndError replaces the failing fields with "[ENCRYPT-ERR]", encrypts the others
+	// and returns this copy along with the first error, so no plaintext is returned. It is the default.
+	// A walk that can't complete, e.g. on a tagged number field or a cancelled context, returns
+	// the zero value along with the error.
+	EncryptFailureBlankAndError EncryptFailurePolicy = iota
+	// EncryptFailureReturnOriginal aborts on the first failure, returning the input unchanged with the error.
+	EncryptFailureReturnOriginal
+	// EncryptFailureBestEffort is EncryptFailureBlankAndError without returning the error.
+	EncryptFailureBestEffort
+)
+
+var encryptFailurePolicy atomic.Int32
+
+// SetEncryptFailurePolicy sets what the encrypt walkers return when encrypting a field fails.
+func SetEncryptFailurePolicy(policy EncryptFailurePolicy) {
+	encryptFailurePolicy.Store(int32(policy))
+}
+
+var autoEncryptPatterns atomic.Pointer[[]string]
+
+// SetAutoEncryptFieldPatterns sets the patterns of the names of the fields StructEncryptByName encrypts
+// without a tag, like "*Password*" or "SSN". The patterns are matched case-insensitively with path.Match,
+// a malformed pattern matching nothing.
+func SetAutoEncryptFieldPatterns(patterns []string) {
+	lower := make([]string, len(patterns))
+	for i, p := range patterns {
+		lower[i] = strings.ToLower(p)
+	}
+	autoEncryptPatterns.Store(&lower)
+}
+
+// matchAutoEncryptField reports whether name matches a pattern set by SetAutoEncryptFieldPatterns.
+func matchAutoEncryptField(name string) bool {
+	patterns := autoEncryptPatterns.Load()
+	if patterns == nil {
+		return false
+	}
+
+	name = strings.ToLower(name)
+	for _, p := range *patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TagValKeyPrefix prefixes the tag values selecting the key registered under the rest of the value,
+// e.g. `encrypt:"enc:health"` encrypts the field with the key registered as "health".
+const TagValKeyPrefix = "enc:"
+
+var namedKeys sync.Map // key name -> hex key
+
+// TagValTime is the tag value marking the time.Time fields encrypted as RFC 3339 strings. A time.Time
+// or *time.Time field can't hold its ciphertext, so it names a companion string field of the same struct
+// after TagValTimePrefix, e.g. `log:"enc-time:BirthDateEnc"`, that is set to the ciphertext while the
+// time is zeroed. An interface{} field holding a time.Time is replaced with its ciphertext.
+const (
+	TagValTime       = "enc-time"
+	TagValTimePrefix = TagValTime + ":"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// RegisterKey registers the hex key under name, for the fields tagged with TagValKeyPrefix + name.
+func RegisterKey(name, key string) {
+	namedKeys.Store(name, key)
+}
+
+// registeredKey returns the key registered under name.
+func registeredKey(name string) (string, error) {
+	key, ok := namedKeys.Load(name)
+	if !ok {
+		return "", fmt.Errorf("%w: no key registered as %q", ErrNoKey, name)
+	}
+	return key.(string), nil
+}
+
+// visitKey identifies a pointer already walked, used to break cycles.
+// Copy also keys the maps and slices it visits, with the length of the slices.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// tagWalker applies crypt to the fields tagged `tagName:"tagVal"` of a value, in place,
+// cryptFPE to the fields tagged `tagName:"fpe"` and cryptDet to the fields tagged `tagName:"enc-det"`.
+type tagWalker struct {
+	key       string
+	tagName   string
+	tagVal    string
+	tagVals   []string // other tag values selecting the fields, along with tagVal
+	crypt     func(text, key string) (string, error)
+	cryptFPE  func(text, key string) (string, error)
+	cryptDet  func(text, key string) (string, error)
+	skipEmpty bool
+	decrypt   bool
+	visited   map[visitKey]bool      // allocated on the first pointer walked
+	ctx       context.Context        // checked before walking each struct, nil to never abort
+	exclude   map[string]bool        // paths of the fields left untouched, nil for none
+	prefix    string                 // path of the struct walked, maintained only with exclude
+	match     func(name string) bool // also selects the untagged fields by name, nil for none
+	policy    EncryptFailurePolicy   // what a failure of crypt does, EncryptFailureReturnOriginal to decrypt
+	failed    error                  // first failure of crypt blanked by the policy
+}
+
+// newTagWalker creates a walker encrypting, or decrypting when decrypt is true, the tagged fields.
+func newTagWalker(key, tagName, tagVal string, decrypt bool) *tagWalker {
+	w := &tagWalker{
+		key:      key,
+		tagName:  tagName,
+		tagVal:   tagVal,
+		crypt:    Encrypt,
+		cryptFPE: EncryptFPE,
+		cryptDet: EncryptDeterministic,
+		// an empty string is never a ciphertext
+		skipEmpty: decrypt || !encryptEmpty.Load(),
+		decrypt:   decrypt,
+		policy:    EncryptFailurePolicy(encryptFailurePolicy.Load()),
+	}
+	if decrypt {
+		w.crypt = Decrypt
+		w.cryptFPE = DecryptFPE
+		w.cryptDet = Decrypt
+		w.policy = EncryptFailureReturnOriginal
+	} else if !w.skipEmpty {
+		// Encrypt keeps "" as is
+		w.crypt = encryptCBC
+		w.cryptDet = func(text, key string) (string, error) {
+			return encryptCBCWith(text, key, true)
+		}
+	}
+	return w
+}
+
+// copyValue deep copies input into a settable reflect.Value.
+func copyValue(input interface{}) reflect.Value {
+	original := reflect.ValueOf(input)
+	v := reflect.New(original.Type()).Elem()
+	copyInto(original, v)
+	return v
+}
+
+// copyStruct walks a deep copy of input, which must be a struct or a pointer to struct.
+// On failure, it returns a nil copy, unless the failure policy returns the copy with the error.
+func (w *tagWalker) copyStruct(input interface{}) (interface{}, error) {
+	v := copyValue(input)
+
+	var err error
+	switch {
+	case v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct:
+		err = w.walkPtr(v, 0)
+	case v.Kind() == reflect.Struct:
+		err = w.walkStruct(v, 0)
+	default:
+		return nil, ErrNotStruct
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Interface(), w.blankedErr()
+}
+
+// copySlice walks a deep copy of input, which must be a slice, as copyStruct.
+func (w *tagWalker) copySlice(input interface{}) (interface{}, error) {
+	v := copyValue(input)
+
+	if v.Kind() != reflect.Slice {
+		return nil, ErrNotSlice
+	}
+
+	if err := w.walkSlice(v, 0); err != nil {
+		return nil, err
+	}
+
+	return v.Interface(), w.blankedErr()
+}
+
+// blankedErr returns the first failure blanked by the walk when the policy reports it.
+func (w *tagWalker) blankedErr() error {
+	if w.policy == EncryptFailureBlankAndError {
+		return w.failed
+	}
+	return nil
+}
+
+// walkStruct applies crypt to the tagged fields of the struct v and recurses into nested structs.
+func (w *tagWalker) walkStruct(v reflect.Value, depth int) error {
+	if limit := currentMaxWalkDepth(); depth > limit {
+		return fmt.Errorf("max walk depth %d exceeded", limit)
+	}
+
+	if w.ctx != nil {
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	if isOpaqueType(v.Type()) {
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+
+		// skip unexported field, it can't be read or set through reflection,
+		// but for the exported fields promoted from an embedded struct
+		if t.Field(i).PkgPath != "" && !isEmbeddedStruct(t.Field(i)) {
+			continue
+		}
+
+		// a field omitted from the JSON encoding is never logged, leave it as is
+		if _, ok := jsonName(t.Field(i)); !ok {
+			continue
+		}
+
+		if w.exclude != nil && w.exclude[w.prefix+t.Field(i).Name] {
+			continue
+		}
+
+		tag := t.Field(i).Tag.Get(w.tagName)
+		tagged := tag == w.tagVal || (tag != "" && slices.Contains(w.tagVals, tag))
+		key := w.key
+		crypt := w.crypt
+		switch tag {
+		case TagValDeterministic:
+			tagged = true
+			crypt = w.cryptDet
+		case TagValFPE:
+			tagged = true
+			crypt = w.cryptFPE
+		}
+
+		var err error
+		if name, ok := strings.CutPrefix(tag, TagValKeyPrefix); ok {
+			tagged = true
+			if key, err = registeredKey(name); err != nil {
+				return err
+			}
+		}
+		// a field selected by name is encrypted when it holds strings, and walked otherwise
+		matched := tagged || (tag == "" && w.match != nil && w.match(t.Field(i).Name))
+
+		switch {
+		case tag == TagValTime || strings.HasPrefix(tag, TagValTimePrefix):
+			err = w.cryptTime(v, t.Field(i), field, tag, key)
+		case matched && field.Kind() == reflect.String:
+			err = w.cryptStringWith(field, crypt, key)
+		case matched && field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.String:
+			if w.visit(field) {
+				err = w.cryptStringWith(field.Elem(), crypt, key)
+			}
+		case matched && field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+			for j := 0; j < field.Len() && err == nil; j++ {
+				err = w.cryptStringWith(field.Index(j), crypt, key)
+			}
+		case matched && field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Ptr &&
+			field.Type().Elem().Elem().Kind() == reflect.String:
+			for j := 0; j < field.Len() && err == nil; j++ {
+				if item := field.Index(j); w.visit(item) {
+					err = w.cryptStringWith(item.Elem(), crypt, key)
+				}
+			}
+		case matched && field.Type() == nullStringType:
+			err = w.cryptStringWith(field.Field(0), crypt, key)
+		case matched && field.Type() == rawMessageType:
+			err = w.cryptRawMessage(field, crypt, key)
+		case tagged && isNumberType(field.Type()):
+			// a number can't hold its ciphertext, fail rather than logging it in plaintext
+			err = fmt.Errorf("%w: field %s of type %s is tagged for encryption but can't hold a ciphertext, change its type to string",
+				ErrUnsettable, t.Field(i).Name, field.Type())
+		case field.Kind() == reflect.Struct:
+			err = w.walkField(t.Field(i), func() error { return w.walkStruct(field, depth+1) })
+		case field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct:
+			// nil pointers, including nil embedded pointers, are skipped by walkPtr
+			err = w.walkField(t.Field(i), func() error { return w.walkPtr(field, depth+1) })
+		case field.Kind() == reflect.Interface:
+			err = w.walkField(t.Field(i), func() error { return w.walkInterface(field, depth+1) })
+		case isStructSlice(field.Type()):
+			// the items are walked whatever the tag, like a nested struct
+			err = w.walkField(t.Field(i), func() error { return w.walkSlice(field, depth) })
+		case field.Kind() == reflect.Ptr && isStructSlice(field.Type().Elem()):
+			if !field.IsNil() {
+				err = w.walkField(t.Field(i), func() error { return w.walkSlice(field.Elem(), depth) })
+			}
+		case field.Kind() == reflect.Map && isStructElem(field.Type().Elem()):
+			err = w.walkField(t.Field(i), func() error { return w.walkMap(field, depth) })
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkField runs walk, which recurses into the struct field f, with the path of f as prefix.
+// The fields of an embedded struct are promoted, so they keep the prefix of the outer struct.
+func (w *tagWalker) walkField(f reflect.StructField, walk func() error) error {
+	if w.exclude == nil || f.Anonymous {
+		return walk()
+	}
+
+	prefix := w.prefix
+	w.prefix += f.Name + "."
+	err := walk()
+	w.prefix = prefix
+	return err
+}
+
+// walkInterface walks the struct or pointer to struct held by the interface v,
+// keeping the interface type of v.
+func (w *tagWalker) walkInterface(v reflect.Value, depth int) error {
+	if v.IsNil() {
+		return nil
+	}
+
+	elem := v.Elem()
+	switch {
+	case elem.Kind() == reflect.Struct:
+		// the value held by an interface isn't settable, walk a copy and set it back
+		cpy := reflect.New(elem.Type()).Elem()
+		cpy.Set(elem)
+		if err := w.walkStruct(cpy, depth); err != nil {
+			return err
+		}
+		v.Set(cpy)
+	case elem.Kind() == reflect.Ptr && elem.Type().Elem().Kind() == reflect.Struct:
+		return w.walkPtr(elem, depth)
+	}
+
+	return nil
+}
+
+// walkPtr walks the struct pointed to by v, visiting each pointer at most once.
+func (w *tagWalker) walkPtr(v reflect.Value, depth int) error {
+	if !w.visit(v) {
+		return nil
+	}
+
+	return w.walkStruct(v.Elem(), depth)
+}
+
+// visit reports whether the pointer v is non-nil and not visited yet, marking it as visited,
+// so that a value shared by several pointers is encrypted or decrypted once.
+func (w *tagWalker) visit(v reflect.Value) bool {
+	if v.IsNil() {
+		return false
+	}
+
+	key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+	if w.visited[key] {
+		return false
+	}
+	if w.visited == nil {
+		w.visited = make(map[visitKey]bool)
+	}
+	w.visited[key] = true
+	return true
+}
+
+// walkSlice walks each struct or pointer to struct item of the slice v.
+func (w *tagWalker) walkSlice(v reflect.Value, depth int) error {
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+
+		var err error
+		switch {
+		case item.Kind() == reflect.Struct:
+			err = w.walkStruct(item, depth+1)
+		case item.Kind() == reflect.Ptr && item.Type().Elem().Kind() == reflect.Struct:
+			err = w.walkPtr(item, depth+1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonName returns the key of the struct field f in its JSON encoding,
+// or false when the field is omitted with `json:"-"`.
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+// walkMap walks each struct or pointer to struct value of the map v.
+// The map values aren't addressable, so a struct value is walked in a copy set back in v,
+// which is the walker's own copy of the map.
+func (w *tagWalker) walkMap(v reflect.Value, depth int) error {
+	iter := v.MapRange()
+	for iter.Next() {
+		item := iter.Value()
+
+		var err error
+		switch {
+		case item.Kind() == reflect.Struct:
+			cpy := reflect.New(item.Type()).Elem()
+			cpy.Set(item)
+			if err = w.walkStruct(cpy, depth+1); err == nil {
+				v.SetMapIndex(iter.Key(), cpy)
+			}
+		case item.Kind() == reflect.Ptr:
+			err = w.walkPtr(item, depth+1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isStructSlice reports whether t is a slice of struct or of pointer to struct.
+func isStructSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && isStructElem(t.Elem())
+}
+
+// isStructElem reports whether t is a struct or a pointer to struct.
+func isStructElem(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// isNumberType reports whether t is a number or a pointer to a number, nil or not.
+func isNumberType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return isNumberKind(t.Kind())
+}
+
+// isNumberKind reports whether k is an integer or float kind.
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// cryptRawMessage replaces the json.RawMessage v with the ciphertext of its bytes as a JSON string,
+// so that it stays valid JSON, or, when decrypting, such a JSON string with the plaintext bytes.
+func (w *tagWalker) cryptRawMessage(v reflect.Value, crypt func(text, key string) (string, error), key string) error {
+	if !v.CanSet() {
+		return ErrUnsettable
+	}
+
+	// an empty RawMessage, like a nil one, marshals to null and holds nothing to encrypt
+	if v.Len() == 0 {
+		return nil
+	}
+
+	var quoted string
+	isString := json.Unmarshal(v.Bytes(), &quoted) == nil
+	text := string(v.Bytes())
+	switch {
+	case w.decrypt && !isString:
+		return ErrDecrypt
+	case w.decrypt:
+		text = quoted
+	case isString && isEncryptedWith(quoted, key):
+		// already encrypted, like a string field
+		return nil
+	}
+
+	s := reflect.New(reflect.TypeOf(text)).Elem()
+	s.SetString(text)
+	if err := w.cryptStringWith(s, crypt, key); err != nil {
+		return err
+	}
+
+	result := []byte(s.String())
+	if !w.decrypt {
+		result, _ = json.Marshal(s.String())
+	}
+	v.SetBytes(result)
+	return nil
+}
+
+// cryptTime encrypts the time of the field f of the struct v tagged TagValTime, into its companion
+// string field, or decrypts it from there.
+func (w *tagWalker) cryptTime(v reflect.Value, f reflect.StructField, field reflect.Value, tag, key string) error {
+	companionName, hasCompanion := strings.CutPrefix(tag, TagValTimePrefix)
+	switch {
+	case !hasCompanion && field.Kind() == reflect.Interface:
+		return w.cryptTimeInterface(field, key)
+	case !hasCompanion || (field.Type() != timeType && field.Type() != reflect.PointerTo(timeType)):
+		return fmt.Errorf("%w: field %s of type %s is tagged %s but can't hold a ciphertext, name a companion string field as %q",
+			ErrUnsettable, f.Name, field.Type(), tag, TagValTimePrefix+"Name")
+	}
+
+	companion := v.FieldByName(companionName)
+	if !companion.IsValid() || companion.Kind() != reflect.String {
+		return fmt.Errorf("%w: companion %s of field %s is not a string field", ErrUnsettable, companionName, f.Name)
+	}
+	if !field.CanSet() || !companion.CanSet() {
+		return ErrUnsettable
+	}
+
+	if w.decrypt {
+		if companion.Len() == 0 {
+			return nil
+		}
+		tm, err := w.decryptTime(companion.String(), key)
+		if err != nil {
+			return err
+		}
+		if field.Kind() == reflect.Ptr {
+			field.Set(reflect.ValueOf(&tm))
+		} else {
+			field.Set(reflect.ValueOf(tm))
+		}
+		companion.SetString("")
+		return nil
+	}
+
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		return nil
+	}
+	tm := reflect.Indirect(field).Interface().(time.Time)
+	if tm.IsZero() && w.skipEmpty {
+		return nil
+	}
+
+	// the pointer is replaced rather than the time it points to, which the input may share
+	field.SetZero()
+	companion.SetString(tm.Format(time.RFC3339Nano))
+	return w.cryptStringWith(companion, w.crypt, key)
+}
+
+// cryptTimeInterface replaces the time.Time held by the interface{} field with its ciphertext,
+// or, when decrypting, such a ciphertext with the time.
+func (w *tagWalker) cryptTimeInterface(field reflect.Value, key string) error {
+	if !field.CanSet() {
+		return ErrUnsettable
+	}
+	if field.IsNil() {
+		return nil
+	}
+
+	if w.decrypt {
+		text, ok := field.Elem().Interface().(string)
+		if !ok || text == "" {
+			return nil
+		}
+		tm, err := w.decryptTime(text, key)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(tm))
+		return nil
+	}
+
+	tm, ok := field.Elem().Interface().(time.Time)
+	if !ok || (tm.IsZero() && w.skipEmpty) {
+		return nil
+	}
+	s := reflect.New(reflect.TypeOf("")).Elem()
+	s.SetString(tm.Format(time.RFC3339Nano))
+	if err := w.cryptStringWith(s, w.crypt, key); err != nil {
+		return err
+	}
+	field.Set(s)
+	return nil
+}
+
+// decryptTime decrypts and parses the ciphertext of a time encrypted by cryptTime.
+func (w *tagWalker) decryptTime(ciphertext, key string) (time.Time, error) {
+	text, err := w.crypt(ciphertext, key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, text)
+}
+
+// cryptStringWith replaces the string value v with its crypt result with key.
+func (w *tagWalker) cryptStringWith(v reflect.Value, crypt func(text, key string) (string, error), key string) error {
+	if !v.CanSet() {
+		return ErrUnsettable
+	}
+
+	if v.Len() == 0 && w.skipEmpty {
+		return nil
+	}
+
+	result, err := crypt(v.String(), key)
+	if err != nil {
+		if w.policy == EncryptFailureReturnOriginal {
+			return err
+		}
+		if w.failed == nil {
+			w.failed = err
+		}
+		result = encryptErrValue
+	}
+	v.SetString(result)
+	return nil
+}
@@ -0,0 +1,594 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type treeNode struct {
+	Secret   string `encrypt:"true"`
+	Parent   *treeNode
+	Children []*treeNode
+}
+
+func TestStructEncryptTagCycle(t *testing.T) {
+	root := &treeNode{Secret: "root"}
+	child := &treeNode{Secret: "child", Parent: root}
+	root.Children = []*treeNode{child}
+
+	encrypted, err := StructEncryptTag(root, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mustDecrypt(t, encrypted.Secret) != "root" || mustDecrypt(t, encrypted.Children[0].Secret) != "child" {
+		t.Errorf("secrets not encrypted once: %q, %q", encrypted.Secret, encrypted.Children[0].Secret)
+	}
+	if encrypted.Children[0].Parent != encrypted {
+		t.Error("the cycle isn't kept in the copy")
+	}
+	if root.Secret != "root" {
+		t.Error("StructEncryptTag modified its input")
+	}
+}
+
+func TestSetMaxWalkDepth(t *testing.T) {
+	SetMaxWalkDepth(3)
+	t.Cleanup(func() { maxWalkDepth.Store(0) })
+
+	// a chain of 5 distinct nodes, deeper than the limit
+	var head *treeNode
+	for i := 0; i < 5; i++ {
+		head = &treeNode{Secret: "s", Children: []*treeNode{head}}
+	}
+
+	_, err := StructEncryptTag(head, testKey, TagNameEncrypt, TagValEncrypt)
+	if err == nil || !strings.Contains(err.Error(), "max walk depth 3 exceeded") {
+		t.Errorf("err = %v, want the max walk depth error", err)
+	}
+}
+
+type secreter interface{ isSecreter() }
+
+type interfaceSecret struct {
+	Secret string `encrypt:"true"`
+}
+
+func (interfaceSecret) isSecreter() {}
+
+func TestStructEncryptTagInterfaceField(t *testing.T) {
+	type holder struct {
+		Any   interface{}
+		Named secreter
+		Ptr   interface{}
+		Nil   interface{}
+	}
+	input := holder{
+		Any:   interfaceSecret{"s1"},
+		Named: interfaceSecret{"s2"},
+		Ptr:   &interfaceSecret{"s3"},
+	}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, ok := encrypted.Any.(interfaceSecret)
+	if !ok || mustDecrypt(t, value.Secret) != "s1" {
+		t.Errorf("interface{} field = %#v, want the struct value with its secret encrypted", encrypted.Any)
+	}
+	named, ok := encrypted.Named.(interfaceSecret)
+	if !ok || mustDecrypt(t, named.Secret) != "s2" {
+		t.Errorf("named interface field = %#v, want the struct value with its secret encrypted", encrypted.Named)
+	}
+	ptr, ok := encrypted.Ptr.(*interfaceSecret)
+	if !ok || mustDecrypt(t, ptr.Secret) != "s3" {
+		t.Errorf("pointer field = %#v, want the pointer with its secret encrypted", encrypted.Ptr)
+	}
+	if encrypted.Nil != nil {
+		t.Errorf("nil interface field = %#v, want it left nil", encrypted.Nil)
+	}
+	if input.Any.(interfaceSecret).Secret != "s1" || input.Ptr.(*interfaceSecret).Secret != "s3" {
+		t.Error("StructEncryptTag modified its input")
+	}
+
+	decrypted, err := StructDecryptTag(encrypted, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Any.(interfaceSecret).Secret != "s1" || decrypted.Ptr.(*interfaceSecret).Secret != "s3" {
+		t.Errorf("decrypted = %#v, want the plaintexts back", decrypted)
+	}
+}
+
+func TestStructEncryptTagEmptyFields(t *testing.T) {
+	type dto struct {
+		Secret string   `encrypt:"true"`
+		Ptr    *string  `encrypt:"true"`
+		Empty  *string  `encrypt:"true"`
+		Tokens []string `encrypt:"true"`
+	}
+	empty := ""
+	input := dto{Empty: &empty, Tokens: []string{""}}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted.Secret != "" || encrypted.Ptr != nil || *encrypted.Empty != "" || encrypted.Tokens[0] != "" {
+		t.Errorf("encrypted = %+v, want the empty fields left empty by default", encrypted)
+	}
+
+	EncryptEmptyFields(true)
+	t.Cleanup(func() { EncryptEmptyFields(false) })
+
+	encrypted, err = StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []string{encrypted.Secret, *encrypted.Empty, encrypted.Tokens[0]} {
+		if !IsEncrypted(got) || mustDecrypt(t, got) != "" {
+			t.Errorf("field = %q, want a ciphertext of the empty string", got)
+		}
+	}
+	if encrypted.Ptr != nil {
+		t.Errorf("nil pointer = %q, want it left nil", *encrypted.Ptr)
+	}
+}
+
+func TestStructEncryptTagNumericField(t *testing.T) {
+	type account struct {
+		Number int64 `encrypt:"true"`
+	}
+	type balance struct {
+		Amount *float64 `encrypt:"true"`
+	}
+	untagged := struct{ Number int64 }{42}
+
+	_, err := StructEncryptTag(account{Number: 42}, testKey, TagNameEncrypt, TagValEncrypt)
+	if !errors.Is(err, ErrUnsettable) || !strings.Contains(err.Error(), "change its type to string") {
+		t.Errorf("err = %v, want ErrUnsettable suggesting a string field", err)
+	}
+	_, err = StructEncryptTag(balance{}, testKey, TagNameEncrypt, TagValEncrypt)
+	if !errors.Is(err, ErrUnsettable) {
+		t.Errorf("err = %v, want ErrUnsettable for a nil pointer to a number too", err)
+	}
+
+	if got, err := StructEncryptTag(untagged, testKey, TagNameEncrypt, TagValEncrypt); err != nil || got.Number != 42 {
+		t.Errorf("untagged number = %v, %v, want it left as is", got.Number, err)
+	}
+}
+
+func TestStructEncryptTagContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	root := &treeNode{Secret: "root", Children: []*treeNode{{Secret: "child"}}}
+	_, err := StructEncryptTagContext(ctx, root, testKey, TagNameEncrypt, TagValEncrypt)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+
+	_, err = InterfaceEncryptTagContext(ctx, root, testKey, TagNameEncrypt, TagValEncrypt)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("interface err = %v, want context.Canceled", err)
+	}
+}
+
+func TestStructEncryptTagExcept(t *testing.T) {
+	type billing struct {
+		Card  string `encrypt:"true"`
+		Email string `encrypt:"true"`
+	}
+	type order struct {
+		EmbeddedSecret
+		Card    string `encrypt:"true"`
+		Billing billing
+	}
+	input := order{EmbeddedSecret{"promoted"}, "top", billing{"nested", "mail"}}
+
+	encrypted, err := StructEncryptTagExcept(input, testKey, TagNameEncrypt, TagValEncrypt, "Billing.Card", "Secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted.Billing.Card != "nested" || encrypted.Secret != "promoted" {
+		t.Errorf("excluded fields = %q, %q, want them in plaintext", encrypted.Billing.Card, encrypted.Secret)
+	}
+	if mustDecrypt(t, encrypted.Card) != "top" || mustDecrypt(t, encrypted.Billing.Email) != "mail" {
+		t.Errorf("encrypted = %+v, want the other tagged fields encrypted, including the top-level Card", encrypted)
+	}
+}
+
+func TestStructEncryptTagNamedKeys(t *testing.T) {
+	const healthKey = "101112131415161718191a1b1c1d1e1f"
+	const financialKey = "202122232425262728292a2b2c2d2e2f"
+	RegisterKey("health", healthKey)
+	RegisterKey("financial", financialKey)
+	t.Cleanup(func() {
+		namedKeys.Delete("health")
+		namedKeys.Delete("financial")
+	})
+
+	type patient struct {
+		Diagnosis string `encrypt:"enc:health"`
+		IBAN      string `encrypt:"enc:financial"`
+		Name      string `encrypt:"true"`
+	}
+	input := patient{Diagnosis: "flu", IBAN: "FR76", Name: "bob"}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []struct{ ciphertext, key, want string }{
+		{encrypted.Diagnosis, healthKey, "flu"},
+		{encrypted.IBAN, financialKey, "FR76"},
+		{encrypted.Name, testKey, "bob"},
+	} {
+		if got, err := Decrypt(field.ciphertext, field.key); err != nil || got != field.want {
+			t.Errorf("Decrypt(%q) with its key = %q, %v, want %q", field.ciphertext, got, err, field.want)
+		}
+	}
+	if _, err := Decrypt(encrypted.Diagnosis, financialKey); err == nil {
+		t.Error("the health field decrypts with the financial key")
+	}
+
+	decrypted, err := StructDecryptTag(encrypted, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != input {
+		t.Errorf("decrypted = %+v, want %+v", decrypted, input)
+	}
+}
+
+func TestStructEncryptTagUnregisteredKey(t *testing.T) {
+	type dto struct {
+		Secret string `encrypt:"enc:unknown"`
+	}
+
+	_, err := StructEncryptTag(dto{Secret: "s"}, testKey, TagNameEncrypt, TagValEncrypt)
+	if !errors.Is(err, ErrNoKey) || !strings.Contains(err.Error(), `"unknown"`) {
+		t.Errorf("err = %v, want ErrNoKey naming the key", err)
+	}
+}
+
+func TestSetEncryptFailurePolicy(t *testing.T) {
+	// the key registered as broken fails every encryption of the fields using it
+	RegisterKey("broken", "zz")
+	t.Cleanup(func() {
+		namedKeys.Delete("broken")
+		SetEncryptFailurePolicy(EncryptFailureBlankAndError)
+	})
+
+	type dto struct {
+		A string `encrypt:"true"`
+		B string `encrypt:"enc:broken"`
+		C string `encrypt:"true"`
+	}
+	input := dto{A: "a", B: "b", C: "c"}
+
+	tests := []struct {
+		policy    EncryptFailurePolicy
+		wantErr   bool
+		wantBlank bool
+	}{
+		{EncryptFailureBlankAndError, true, true},
+		{EncryptFailureBestEffort, false, true},
+		{EncryptFailureReturnOriginal, true, false},
+	}
+	for _, tt := range tests {
+		SetEncryptFailurePolicy(tt.policy)
+
+		got, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("policy %d: err = %v, want an error %v", tt.policy, err, tt.wantErr)
+		}
+		if tt.wantBlank {
+			if got.B != encryptErrValue || mustDecrypt(t, got.A) != "a" || mustDecrypt(t, got.C) != "c" {
+				t.Errorf("policy %d: got %+v, want B blanked and the other fields encrypted", tt.policy, got)
+			}
+		} else if got != input {
+			t.Errorf("policy %d: got %+v, want the input", tt.policy, got)
+		}
+
+		slice, err := StringSliceEncrypt([]string{"a", "b"}, "zz")
+		if err == nil {
+			t.Errorf("policy %d: StringSliceEncrypt with a broken key returned no error", tt.policy)
+		}
+		if tt.policy == EncryptFailureReturnOriginal {
+			if !reflect.DeepEqual(slice, []string{"a", "b"}) {
+				t.Errorf("policy %d: StringSliceEncrypt = %v, want the input", tt.policy, slice)
+			}
+		} else if slice != nil {
+			t.Errorf("policy %d: StringSliceEncrypt = %v, want no plaintext returned", tt.policy, slice)
+		}
+	}
+
+	prev, broken := keyEncrypt, "zz"
+	keyEncrypt = &broken
+	t.Cleanup(func() { keyEncrypt = prev })
+	SetEncryptFailurePolicy(EncryptFailureBlankAndError)
+	if got, err := EncryptLog("secret"); err == nil || got != "" {
+		t.Errorf("EncryptLog = %q, %v, want no plaintext returned with the error", got, err)
+	}
+	SetEncryptFailurePolicy(EncryptFailureReturnOriginal)
+	if got, err := EncryptLog("secret"); err == nil || got != "secret" {
+		t.Errorf("EncryptLog = %q, %v, want the input returned with the error", got, err)
+	}
+}
+
+// testDecimal stands for a type like decimal.Decimal, whose state is in unexported fields.
+type testDecimal struct {
+	value int64
+	exp   int32
+}
+
+func TestRegisterOpaqueType(t *testing.T) {
+	RegisterOpaqueType(reflect.TypeOf(testDecimal{}))
+	t.Cleanup(func() { opaqueTypes.Delete(reflect.TypeOf(testDecimal{})) })
+
+	type payment struct {
+		ID      [16]byte // a uuid.UUID
+		Amount  testDecimal
+		Fee     *testDecimal
+		At      time.Time
+		Account sql.NullString `encrypt:"true"`
+		Memo    sql.NullString
+	}
+	input := payment{
+		ID:      [16]byte{1, 2, 3},
+		Amount:  testDecimal{1234, -2},
+		Fee:     &testDecimal{5, 1},
+		At:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Account: sql.NullString{String: "FR76", Valid: true},
+		Memo:    sql.NullString{String: "memo", Valid: true},
+	}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted.ID != input.ID || encrypted.Amount != input.Amount || *encrypted.Fee != *input.Fee || !encrypted.At.Equal(input.At) {
+		t.Errorf("encrypted = %+v, want the opaque values copied whole", encrypted)
+	}
+	if encrypted.Fee == input.Fee {
+		t.Error("the pointer to an opaque value is shared with the input")
+	}
+	if !encrypted.Account.Valid || mustDecrypt(t, encrypted.Account.String) != "FR76" {
+		t.Errorf("account = %+v, want its inner string encrypted", encrypted.Account)
+	}
+	if encrypted.Memo != input.Memo {
+		t.Errorf("memo = %+v, want the untagged NullString left as is", encrypted.Memo)
+	}
+}
+
+func TestStructEncryptTagSliceOfStructFields(t *testing.T) {
+	type sub struct {
+		Secret string `encrypt:"true"`
+	}
+	type payload struct {
+		Ptrs     []*sub
+		PtrSlice *[]sub
+		NilSlice *[]sub
+	}
+	items := []sub{{"v1"}, {"v2"}}
+	input := payload{Ptrs: []*sub{{"p1"}, nil, {"p2"}}, PtrSlice: &items}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mustDecrypt(t, encrypted.Ptrs[0].Secret) != "p1" || encrypted.Ptrs[1] != nil || mustDecrypt(t, encrypted.Ptrs[2].Secret) != "p2" {
+		t.Errorf("[]*sub = %v, want the secrets of each element encrypted", encrypted.Ptrs)
+	}
+	for i, item := range *encrypted.PtrSlice {
+		if mustDecrypt(t, item.Secret) != items[i].Secret {
+			t.Errorf("*[]sub element %d = %q, want its secret encrypted", i, item.Secret)
+		}
+	}
+	if encrypted.NilSlice != nil {
+		t.Error("nil pointer to a slice isn't kept nil")
+	}
+	if input.Ptrs[0].Secret != "p1" || items[0].Secret != "v1" {
+		t.Error("StructEncryptTag modified its input")
+	}
+}
+
+type JSONTokens struct {
+	Token string `json:"tok" encrypt:"true"`
+}
+
+func TestStructEncryptTagJSONNames(t *testing.T) {
+	type card struct {
+		JSONTokens
+		Number string `json:"card.number" encrypt:"true"`
+		Holder string `json:"holder,omitempty"`
+		PIN    string `json:"-" encrypt:"true"`
+		Name   string `encrypt:"true"`
+	}
+	input := card{JSONTokens{"t"}, "4111", "bob", "1234", "n"}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted.PIN != "1234" {
+		t.Errorf("PIN = %q, want the field omitted from the JSON skipped", encrypted.PIN)
+	}
+	line, err := AnyToString(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(line, "1234") || strings.Contains(line, "4111") {
+		t.Fatalf("logged %s, want no plaintext", line)
+	}
+
+	fields := EncryptedJSONFields(&input, TagNameEncrypt, TagValEncrypt)
+	want := map[string]bool{"tok": true, "card.number": true, "Name": true}
+	if len(fields) != len(want) {
+		t.Fatalf("EncryptedJSONFields = %v, want %v", fields, want)
+	}
+	for name := range want {
+		if !fields[name] {
+			t.Errorf("EncryptedJSONFields = %v, want %v", fields, want)
+		}
+	}
+
+	decrypted, err := DecryptLogLine(line, testKey, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(decrypted, `"card.number":"4111"`) || !strings.Contains(decrypted, `"tok":"t"`) || !strings.Contains(decrypted, `"Name":"n"`) {
+		t.Errorf("DecryptLogLine = %s, want the fields decrypted by their JSON names", decrypted)
+	}
+}
+
+func TestStructEncryptTagMapOfStructs(t *testing.T) {
+	type details struct {
+		Number string `encrypt:"true"`
+		Brand  string
+	}
+	type wallet struct {
+		Values   map[string]details
+		Pointers map[string]*details
+		Nil      map[string]details
+	}
+	input := wallet{
+		Values:   map[string]details{"main": {"4111", "visa"}},
+		Pointers: map[string]*details{"backup": {"5500", "mc"}, "none": nil},
+	}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := encrypted.Values["main"]; mustDecrypt(t, got.Number) != "4111" || got.Brand != "visa" {
+		t.Errorf("map value = %+v, want its tagged field encrypted", got)
+	}
+	if got := encrypted.Pointers["backup"]; mustDecrypt(t, got.Number) != "5500" || got.Brand != "mc" {
+		t.Errorf("map pointer = %+v, want its tagged field encrypted", got)
+	}
+	if got, ok := encrypted.Pointers["none"]; !ok || got != nil {
+		t.Errorf("nil map pointer = %v, %t, want it kept nil", got, ok)
+	}
+	if encrypted.Nil != nil {
+		t.Error("nil map isn't kept nil")
+	}
+	if input.Values["main"].Number != "4111" || input.Pointers["backup"].Number != "5500" {
+		t.Error("StructEncryptTag modified its input")
+	}
+
+	decrypted, err := StructDecryptTag(encrypted, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Values["main"].Number != "4111" || decrypted.Pointers["backup"].Number != "5500" {
+		t.Errorf("decrypted = %+v, want the plaintexts back", decrypted)
+	}
+}
+
+func TestStructEncryptTagRawMessage(t *testing.T) {
+	type event struct {
+		Payload json.RawMessage `encrypt:"true"`
+		Plain   json.RawMessage
+		Empty   json.RawMessage `encrypt:"true"`
+	}
+	input := event{Payload: json.RawMessage(`{"pan":"4111"}`), Plain: json.RawMessage(`{"a":1}`)}
+
+	encrypted, err := StructEncryptTag(input, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ciphertext string
+	if err := json.Unmarshal(encrypted.Payload, &ciphertext); err != nil {
+		t.Fatalf("payload %s, want a JSON string: %v", encrypted.Payload, err)
+	}
+	if mustDecrypt(t, ciphertext) != `{"pan":"4111"}` {
+		t.Errorf("payload %s, want the ciphertext of the raw bytes", encrypted.Payload)
+	}
+	if string(encrypted.Plain) != `{"a":1}` || encrypted.Empty != nil {
+		t.Errorf("encrypted %+v, want the untagged and empty messages unchanged", encrypted)
+	}
+	if _, err := json.Marshal(encrypted); err != nil {
+		t.Errorf("the encrypted struct doesn't marshal: %v", err)
+	}
+	if string(input.Payload) != `{"pan":"4111"}` {
+		t.Errorf("input payload %s, want it unchanged", input.Payload)
+	}
+
+	twice, err := StructEncryptTag(encrypted, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil || string(twice.Payload) != string(encrypted.Payload) {
+		t.Errorf("encrypted twice %s, %v, want the payload encrypted once", twice.Payload, err)
+	}
+
+	decrypted, err := StructDecryptTag(encrypted, testKey, TagNameEncrypt, TagValEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decrypted, input) {
+		t.Errorf("decrypted %+v, want %+v", decrypted, input)
+	}
+}
+
+func TestStructEncryptTagTime(t *testing.T) {
+	type appointment struct {
+		Name     string      `log:"true"`
+		Birth    time.Time   `log:"enc-time:BirthEnc"`
+		BirthEnc string      `json:"birth_enc,omitempty"`
+		At       *time.Time  `log:"enc-time:AtEnc"`
+		AtEnc    string      `json:"at_enc,omitempty"`
+		Any      interface{} `log:"enc-time"`
+		Created  time.Time   `log:"true"`
+	}
+	birth := time.Date(1990, 5, 1, 10, 0, 0, 123, time.UTC)
+	at := time.Date(2024, 3, 4, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	input := appointment{Name: "bob", Birth: birth, At: &at, Any: birth, Created: birth}
+
+	encrypted, err := StructEncryptTag(input, testKey, "log", "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encrypted.Birth.IsZero() || encrypted.At != nil {
+		t.Errorf("encrypted %+v, want the tagged times cleared", encrypted)
+	}
+	if mustDecrypt(t, encrypted.BirthEnc) != birth.Format(time.RFC3339Nano) || mustDecrypt(t, encrypted.AtEnc) != at.Format(time.RFC3339Nano) {
+		t.Errorf("encrypted %+v, want the RFC 3339 times encrypted into their companions", encrypted)
+	}
+	if mustDecrypt(t, encrypted.Any.(string)) != birth.Format(time.RFC3339Nano) {
+		t.Errorf("encrypted interface %v, want the ciphertext of the time", encrypted.Any)
+	}
+	if !encrypted.Created.Equal(birth) {
+		t.Errorf("created %v, want the time tagged true left as is", encrypted.Created)
+	}
+	if input.At != &at || !at.Equal(time.Date(2024, 3, 4, 9, 30, 0, 0, time.FixedZone("CET", 3600))) {
+		t.Error("the input time was modified")
+	}
+
+	decrypted, err := StructDecryptTag(encrypted, testKey, "log", "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decrypted.Birth.Equal(birth) || !decrypted.At.Equal(at) || !decrypted.Any.(time.Time).Equal(birth) ||
+		decrypted.BirthEnc != "" || decrypted.AtEnc != "" || decrypted.Name != "bob" {
+		t.Errorf("decrypted %+v, want the input times back", decrypted)
+	}
+
+	type noCompanion struct {
+		At time.Time `log:"enc-time"`
+	}
+	type missingCompanion struct {
+		At time.Time `log:"enc-time:Missing"`
+	}
+	if _, err := StructEncryptTag(noCompanion{At: birth}, testKey, "log", "true"); !errors.Is(err, ErrUnsettable) {
+		t.Errorf("without a companion: err = %v, want ErrUnsettable", err)
+	}
+	if _, err := StructEncryptTag(missingCompanion{At: birth}, testKey, "log", "true"); !errors.Is(err, ErrUnsettable) {
+		t.Errorf("with a missing companion: err = %v, want ErrUnsettable", err)
+	}
+}